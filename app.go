@@ -8,14 +8,21 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+	"time"
 )
 
 // Action defines a function type to be executed for an application or a
 // command. It takes a slice of validated positional arguments and a map
 // of validated options (with all value types encoded as strings) and
-// returns a Unix exit code (success: 0).
+// returns a Unix exit code (success: 0). Arguments following `--` for commands
+// declaring passthrough arguments and the environment the application is run
+// in are available to a ContextAction or an ErrorAction only, see
+// PassthroughFrom and EnvFrom.
 type Action func(args []string, options map[string]string) int
 
 // App defines a CLI application parameterizable with sub-commands, arguments and options.
@@ -24,24 +31,154 @@ type App interface {
 	Description() string
 	// Args returns required and optional positional arguments for the top-level application.
 	Args() []Arg
-	// Options permitted for the top-level application and all sub-commands.
+	// Options returns options permitted for the top-level application and, unless local, all sub-commands.
 	Options() []Option
 	// Commands returns the set of first-level sub-commands for the application.
 	Commands() []Command
 	// Action returns the application action when no sub-command is specified.
 	Action() Action
+	// ContextAction returns the application action receiving a context when no sub-command is specified.
+	ContextAction() ContextAction
+	// ErrorAction returns the application action returning an error when no sub-command is specified.
+	ErrorAction() ErrorAction
+	// Before returns the hooks executed before the action of the application or any sub-command.
+	Before() []Hook
+	// After returns the hooks executed after the action of the application or any sub-command.
+	After() []Hook
+	// Middleware returns the middleware wrapping the execution of the application or any sub-command.
+	Middleware() []Middleware
+	// HelpTopics returns free-form help topics listed in the usage of the top-level application.
+	HelpTopics() []HelpTopic
+	// Examples returns the usage examples of the top-level application.
+	Examples() []Example
+	// Interspersed specifies if options can follow positional arguments of the top-level application
+	// (default), otherwise option parsing stops at the first positional argument.
+	Interspersed() bool
+	// Passthrough returns the definition of arguments following `--` for the top-level application,
+	// nil if not declared.
+	Passthrough() Arg
+	// ResponseFiles specifies if `@path` arguments are expanded into the arguments read from the file at path.
+	ResponseFiles() bool
+	// UsageWidth returns the configured width of the usage output in columns, see WithUsageWidth.
+	UsageWidth() int
+	// UsageRenderer returns the configured renderer of the usage output, nil for the default one.
+	UsageRenderer() UsageRenderer
+	// Version returns the version of the application, empty if not set.
+	Version() string
+	// VersionTemplate returns the configured template of the version output, empty for the default one.
+	VersionTemplate() string
+	// Language returns the configured language of messages, empty if taken from the environment.
+	Language() string
+	// Catalogs returns the registered message catalogs by their language, see WithCatalog.
+	Catalogs() map[string]Catalog
 
 	// WithArg adds a positional argument to the application. Specifying last application/command
 	// argument as optional permits unlimited number of further positional arguments (at least one
 	// optional argument needs to be specified in the definition for this case).
 	WithArg(arg Arg) App
-	// WithOption adds a permitted option to the application and all sub-commands.
+	// WithOption adds a permitted option to the application and all sub-commands, see Option.AsLocal
+	// to restrict it to the application itself.
 	WithOption(opt Option) App
 	// WithCommand adds a first-level sub-command to the application.
 	WithCommand(cmd Command) App
 	// WithAction sets the action function to execute after successful parsing of commands, arguments
 	// and options to the top-level application.
 	WithAction(action Action) App
+	// WithContextAction sets the action function receiving a context, which is cancelled on signals, to
+	// execute after successful parsing of commands, arguments and options to the top-level application.
+	// It takes precedence over the action set with WithAction.
+	WithContextAction(action ContextAction) App
+	// WithErrorAction sets the action function returning an error to execute after successful parsing of
+	// commands, arguments and options to the top-level application, see ErrorAction. It receives a context
+	// same as ContextAction and takes precedence over the actions set with WithContextAction and WithAction.
+	WithErrorAction(action ErrorAction) App
+	// WithBefore adds a hook executed before the action of the application or any of its sub-commands.
+	// Before hooks are executed along the invocation path, the application first, see Hook. Hooks are not
+	// executed for the built-in help, version and completion commands nor for `--help` and `--version`.
+	WithBefore(hook Hook) App
+	// WithAfter adds a hook executed after the action of the application or any of its sub-commands has
+	// succeeded. After hooks are executed along the invocation path in reverse, the application last.
+	// Same as before hooks, after hooks are not executed for the built-in commands, see WithBefore.
+	WithAfter(hook Hook) App
+	// WithMiddleware adds a middleware wrapping the execution of the application or any of its
+	// sub-commands including the hooks. Middleware of the application wraps that of sub-commands. Same
+	// as hooks, middleware does not wrap the built-in commands, see WithBefore.
+	WithMiddleware(mw Middleware) App
+	// WithSignals sets the signals cancelling the context of a ContextAction or ErrorAction, SIGINT and
+	// SIGTERM by default. Without any signals given, signals are not handled. Signals are handled only
+	// while running a ContextAction or ErrorAction, see RunContext.
+	WithSignals(sigs ...os.Signal) App
+	// WithGracePeriod sets the time to wait for a ContextAction or ErrorAction to return after the first
+	// signal has been received, by default Run waits until the action returns or a second signal is received.
+	// When the grace period expires or a second signal is received, Run returns without waiting for the
+	// action: its goroutine keeps running, and nothing collects its result, until the action returns or the
+	// process exits. Actions must therefore not rely on deferred cleanup once Run has returned, and callers
+	// should exit the process soon after.
+	WithGracePeriod(grace time.Duration) App
+	// WithInterspersed permits (default) or forbids options to follow positional arguments of the
+	// top-level application, see Command.WithInterspersed.
+	WithInterspersed(interspersed bool) App
+	// WithPassthrough declares arguments following `--` for the top-level application to be passed
+	// through, see Command.WithPassthrough.
+	WithPassthrough(arg Arg) App
+	// WithResponseFiles enables the expansion of `@path` arguments into the arguments read from the file
+	// at path before the arguments are parsed. Arguments in the file are separated by white space or new
+	// lines and can be quoted as in a shell; empty lines and lines starting with `#` are ignored. Response
+	// files can reference further response files, relative to the referencing file; relative paths given on
+	// the command line are relative to the working directory of the environment run with, see Env.Dir. A
+	// leading `@@` escapes a literal `@`. Arguments following `--` are not expanded. Files that cannot be read
+	// or contain malformed lines are reported as ResponseFileError.
+	WithResponseFiles(enabled bool) App
+	// WithCompletionCommand adds the built-in `completion [--dynamic] <shell>` command outputting the static
+	// completion script for the given shell, see Completion. With `--dynamic` the script delegates to the
+	// hidden `__complete` command added along, which also completes values of options and arguments, see
+	// DynamicCompletion.
+	WithCompletionCommand() App
+	// WithHelpCommand adds the built-in `help [command...]` command outputting the usage for the command
+	// given by its path, e.g. `gitc help remote add`, or the text of a help topic, e.g. `gitc help environment`.
+	WithHelpCommand() App
+	// WithHelpTopic adds a free-form help topic, which is not a command, but is output by the built-in `help`
+	// command and listed in the usage of the top-level application if the former is added, see WithHelpCommand.
+	WithHelpTopic(key, descr, text string) App
+	// WithExample adds a usage example of the top-level application given by the complete command line,
+	// e.g. `git -v status`, and its explanation, see ValidateExamples.
+	WithExample(cmdline, explanation string) App
+	// WithSpecFlag enables the hidden `--cli-spec` flag, which outputs the specification of the application
+	// as JSON when given as the only argument, see WriteSpec.
+	WithSpecFlag() App
+	// WithWarnings sets the writer for warnings, e.g. on the use of deprecated commands, options and
+	// arguments. By default warnings are output to the writer passed into Run or the Err stream.
+	WithWarnings(w io.Writer) App
+	// WithStrictDeprecation turns warnings on the use of deprecated commands, options and arguments
+	// into errors, e.g. to keep CI scripts from relying on deprecated features.
+	WithStrictDeprecation(strict bool) App
+	// WithUsageWidth sets the width in columns the usage output is wrapped to. With AutoUsageWidth the width
+	// is taken from the COLUMNS environment variable or, as shells do not normally export the latter, from
+	// the terminal the usage is output to; the usage is not wrapped if neither is available, e.g. when the
+	// output is redirected to a file. With 0 (default) or any other negative width the usage is not wrapped.
+	WithUsageWidth(width int) App
+	// WithUsageRenderer sets the renderer of the usage output, e.g. one created with NewTemplateRenderer
+	// to apply a house style. By default the usage is rendered by DefaultUsageRenderer.
+	WithUsageRenderer(r UsageRenderer) App
+	// WithVersion sets the version of the application enabling the `--version` (`-V`) flag, which outputs
+	// the version along with the build information of the binary, see VersionInfo. Similarly to `--help`,
+	// the flag is accepted for any command and bypasses the validation of arguments and options. The flag
+	// is not available for commands defining an option with the key `version`, nor is `-V` for those
+	// defining an option with the char key `V`.
+	WithVersion(version string) App
+	// WithVersionTemplate sets the text/template the version is output with, executed with VersionInfo
+	// as data, see DefaultVersionTemplate.
+	WithVersionTemplate(tmpl string) App
+	// WithVersionCommand adds the built-in `version` command outputting the version, or the VersionInfo
+	// as JSON with `--json`, see WithVersion. The command fails if no version is set.
+	WithVersionCommand() App
+	// WithCatalog registers the catalog of messages for the language given by the locale name, e.g. `de`
+	// or `pt_BR`. The catalog is used for errors, warnings and the usage headings if the language is set
+	// with WithLanguage or, otherwise, matches the LC_ALL, LC_MESSAGES or LANG environment variable. Messages
+	// missing in the catalog are output in English.
+	WithCatalog(lang string, c Catalog) App
+	// WithLanguage sets the language of messages overriding the environment, see WithCatalog.
+	WithLanguage(lang string) App
 
 	// Parse parses the original application arguments into the command invocation path (application ->
 	// first level command -> second level command etc.), a list of validated positional arguments matching
@@ -53,10 +190,39 @@ type App interface {
 	Parse(appargs []string) (invocation []string, args []string, opts map[string]string, err error)
 	// Run parses the argument list and runs the command specified with the corresponding options and arguments.
 	Run(appargs []string, w io.Writer) int
-	// Usage prints out the full usage help.
+	// RunContext runs the application same as Run passing the context down to a ContextAction or
+	// ErrorAction. The context passed to the action is additionally cancelled on the first of the
+	// configured signals, see WithSignals. In this case RunContext returns the conventional exit code of
+	// 128 plus the signal number, e.g. 130 for SIGINT, once the action returns, the grace period expires
+	// or a second signal is received, whichever comes first. In the two latter cases the action is not
+	// waited for, see WithGracePeriod.
+	RunContext(ctx context.Context, appargs []string, w io.Writer) int
+	// RunStreams runs the application same as RunContext, but with separate streams: the usage, help and
+	// version are output to Out, while errors, the short usage on errors and warnings are output to Err.
+	// The streams are passed to actions, hooks and middleware through the context, see StreamsFrom. Run and
+	// RunContext output everything to the given writer and pass os.Stdin as input. The remaining environment
+	// is that of the process, see RunEnv.
+	RunStreams(ctx context.Context, appargs []string, streams Streams) int
+	// RunEnv runs the application same as RunStreams in the given environment, which is passed to actions,
+	// hooks and middleware through the context, see EnvFrom. Fields of the environment not set default to
+	// those of the process.
+	RunEnv(ctx context.Context, appargs []string, env Env) int
+	// Usage prints out the full usage help. Hidden commands and options are not included, they are
+	// revealed with `--help-all` instead of `--help`, see UsageAll. The usage width and language are looked
+	// up in the environment of the process, see UsageEnv.
 	Usage(invocation []string, w io.Writer) error
 }
 
+// HelpTopic defines a free-form help topic, e.g. on the environment or configuration files.
+type HelpTopic struct {
+	// Key is the name of the topic as passed to the `help` command, e.g. `environment`.
+	Key string
+	// Description is the short description of the topic listed in the usage.
+	Description string
+	// Text is the full text of the topic output by the `help` command.
+	Text string
+}
+
 // New creates a new CLI App.
 func New(descr string) App {
 	return &app{descr: descr}
@@ -91,11 +257,22 @@ type Arg interface {
 	// should follow an optional one (no validation for this scenario as this is
 	// the definition time exception, rather than incorrect input at runtime).
 	Optional() bool
+	// Deprecated specifies if the argument is deprecated, i.e. still accepted but a warning is issued.
+	Deprecated() bool
+	// Deprecation returns the deprecation notice and the suggested replacement, both may be empty.
+	Deprecation() (notice, replacement string)
+	// Completer returns the function completing argument values, nil if not set.
+	Completer() Completer
 
 	// WithType sets the argument type.
 	WithType(at ValueType) Arg
 	// AsOptional sets the argument as optional.
 	AsOptional() Arg
+	// AsDeprecated marks the argument as deprecated with an optional notice and replacement to be
+	// output in the warning issued when the argument is given and in the usage.
+	AsDeprecated(notice, replacement string) Arg
+	// WithCompleter sets the function completing argument values in the dynamic shell completion.
+	WithCompleter(completer Completer) Arg
 }
 
 // NewArg creates a new positional argument.
@@ -104,11 +281,35 @@ func NewArg(key, descr string) Arg {
 }
 
 type app struct {
-	descr  string
-	args   []Arg
-	opts   []Option
-	cmds   []Command
-	action Action
+	descr    string
+	args     []Arg
+	opts     []Option
+	cmds     []Command
+	action   Action
+	ctxact   ContextAction
+	erract   ErrorAction
+	before   []Hook
+	after    []Hook
+	mws      []Middleware
+	sigs     []os.Signal
+	sigset   bool
+	grace    time.Duration
+	warnw    io.Writer
+	strict   bool
+	nointer  bool
+	passthru Arg
+	respf    bool
+	specf    bool
+	topics   []HelpTopic
+	examples []Example
+	width    int
+	renderer UsageRenderer
+	version  string
+	vtmpl    string
+	lang     string
+	catalogs map[string]Catalog
+	// actionName is the name of the action bound when loading from a specification
+	actionName string
 }
 
 func (a *app) Description() string {
@@ -150,49 +351,317 @@ func (a *app) WithAction(action Action) App {
 	return a
 }
 
+func (a *app) ContextAction() ContextAction {
+	return a.ctxact
+}
+
+func (a *app) WithContextAction(action ContextAction) App {
+	a.ctxact = action
+	return a
+}
+
+func (a *app) ErrorAction() ErrorAction {
+	return a.erract
+}
+
+func (a *app) WithErrorAction(action ErrorAction) App {
+	a.erract = action
+	return a
+}
+
+func (a *app) Before() []Hook {
+	return a.before
+}
+
+func (a *app) WithBefore(hook Hook) App {
+	a.before = append(a.before, hook)
+	return a
+}
+
+func (a *app) After() []Hook {
+	return a.after
+}
+
+func (a *app) WithAfter(hook Hook) App {
+	a.after = append(a.after, hook)
+	return a
+}
+
+func (a *app) Middleware() []Middleware {
+	return a.mws
+}
+
+func (a *app) WithMiddleware(mw Middleware) App {
+	a.mws = append(a.mws, mw)
+	return a
+}
+
+func (a *app) WithSignals(sigs ...os.Signal) App {
+	a.sigs = sigs
+	a.sigset = true
+	return a
+}
+
+func (a *app) WithGracePeriod(grace time.Duration) App {
+	a.grace = grace
+	return a
+}
+
+func (a *app) Interspersed() bool {
+	return !a.nointer
+}
+
+func (a *app) WithInterspersed(interspersed bool) App {
+	a.nointer = !interspersed
+	return a
+}
+
+func (a *app) Passthrough() Arg {
+	return a.passthru
+}
+
+func (a *app) WithPassthrough(arg Arg) App {
+	a.passthru = arg
+	return a
+}
+
+func (a *app) ResponseFiles() bool {
+	return a.respf
+}
+
+func (a *app) WithResponseFiles(enabled bool) App {
+	a.respf = enabled
+	return a
+}
+
+func (a *app) WithCompletionCommand() App {
+	shell := NewArg("shell", "shell to output the script for: bash, zsh, fish or powershell").
+		WithCompleter(func(prefix string, partial ParseResult) []string {
+			return []string{ShellBash, ShellZsh, ShellFish, ShellPowerShell}
+		})
+	words := NewArg("words", "command line words up to the one being completed")
+	dynamic := NewOption(dynamicKey, "Output a script completing values of options and arguments at run time").
+		WithType(TypeBool).AsLocal()
+	return a.WithCommand(&command{
+		key:     "completion",
+		descr:   "Output the shell completion script",
+		args:    []Arg{shell},
+		opts:    []Option{dynamic},
+		builtin: completionAction,
+	}).WithCommand(&command{
+		key:      completeKey,
+		descr:    "Output completion candidates for the command line",
+		passthru: words,
+		hidden:   true,
+		builtin:  completeAction,
+	})
+}
+
+func (a *app) HelpTopics() []HelpTopic {
+	return a.topics
+}
+
+func (a *app) WithHelpCommand() App {
+	return a.WithCommand(&command{
+		key:     helpKey,
+		descr:   "Show the usage for a command or a help topic",
+		args:    []Arg{NewArg("command", "command path or help topic").AsOptional()},
+		builtin: helpAction,
+	})
+}
+
+func (a *app) WithHelpTopic(key, descr, text string) App {
+	a.topics = append(a.topics, HelpTopic{Key: key, Description: descr, Text: text})
+	return a
+}
+
+func (a *app) Examples() []Example {
+	return a.examples
+}
+
+func (a *app) WithExample(cmdline, explanation string) App {
+	a.examples = append(a.examples, Example{Cmdline: cmdline, Explanation: explanation})
+	return a
+}
+
+func (a *app) WithSpecFlag() App {
+	a.specf = true
+	return a
+}
+
+func (a *app) WithWarnings(w io.Writer) App {
+	a.warnw = w
+	return a
+}
+
+func (a *app) WithStrictDeprecation(strict bool) App {
+	a.strict = strict
+	return a
+}
+
+func (a *app) UsageWidth() int {
+	return a.width
+}
+
+func (a *app) WithUsageWidth(width int) App {
+	a.width = width
+	return a
+}
+
+func (a *app) UsageRenderer() UsageRenderer {
+	return a.renderer
+}
+
+func (a *app) WithUsageRenderer(r UsageRenderer) App {
+	a.renderer = r
+	return a
+}
+
+func (a *app) Version() string {
+	return a.version
+}
+
+func (a *app) WithVersion(version string) App {
+	a.version = version
+	return a
+}
+
+func (a *app) VersionTemplate() string {
+	return a.vtmpl
+}
+
+func (a *app) WithVersionTemplate(tmpl string) App {
+	a.vtmpl = tmpl
+	return a
+}
+
+func (a *app) WithVersionCommand() App {
+	cmd := &command{key: versionKey, descr: "Show the version", builtin: versionAction}
+	cmd.WithOption(NewOption(jsonKey, "Output the version information as JSON").WithType(TypeBool).AsLocal())
+	return a.WithCommand(cmd)
+}
+
+func (a *app) Language() string {
+	return a.lang
+}
+
+func (a *app) Catalogs() map[string]Catalog {
+	return a.catalogs
+}
+
+func (a *app) WithCatalog(lang string, c Catalog) App {
+	if a.catalogs == nil {
+		a.catalogs = make(map[string]Catalog)
+	}
+	a.catalogs[lang] = c
+	return a
+}
+
+func (a *app) WithLanguage(lang string) App {
+	a.lang = lang
+	return a
+}
+
 func (a *app) Parse(appargs []string) (invocation []string, args []string, opts map[string]string, err error) {
 	return Parse(a, appargs)
 }
 
 func (a *app) Run(appargs []string, w io.Writer) int {
-	invocation, args, opts, err := a.Parse(appargs)
-	_, help := opts["help"]
+	return a.RunContext(context.Background(), appargs, w)
+}
+
+func (a *app) RunContext(ctx context.Context, appargs []string, w io.Writer) int {
+	return a.RunStreams(ctx, appargs, Streams{In: os.Stdin, Out: w, Err: w})
+}
+
+func (a *app) RunStreams(ctx context.Context, appargs []string, streams Streams) int {
+	return a.RunEnv(ctx, appargs, Env{Streams: streams})
+}
+
+func (a *app) RunEnv(ctx context.Context, appargs []string, env Env) int {
+	ctx, env = withEnv(ctx, env)
+	w, errw := env.Out, env.Err
+	c := catalog(a, env.LookupEnv)
+	if a.specf && len(appargs) == 2 && appargs[1] == specFlag {
+		if err := WriteSpec(a, appname(appargs[0]), w); err != nil {
+			fmt.Fprintln(errw, msg(c, MsgFatal, errmsg(c, err)))
+			return 1
+		}
+		return 0
+	}
+
+	res, err := parseArgs(a, appargs, env.Dir)
+	invocation, opts := res.Invocation, res.Opts
+	_, help := opts[helpKey]
+	version := false
+	if sc, serr := resolve(a, invocation); serr == nil {
+		version = versionRequested(a, sc, opts)
+	}
 	code := 1
-	if err == nil && help {
-		a.Usage(invocation, w)
+	if err == nil && version {
+		if err = writeVersion(a, invocation[0], false, w); err != nil {
+			fmt.Fprintln(errw, msg(c, MsgFatal, errmsg(c, err)))
+		} else {
+			code = 0
+		}
+	} else if _, all := opts[helpAllKey]; err == nil && all {
+		usage(a, invocation, w, true, env.LookupEnv)
+		code = 0
+	} else if err == nil && help {
+		usage(a, invocation, w, false, env.LookupEnv)
 		code = 0
 	} else if err != nil {
-		fmt.Fprintf(w, "fatal: %v\n", err)
-		fmt.Fprintf(w, "usage: %v\n", shortUsage(a, invocation))
+		fmt.Fprintln(errw, msg(c, MsgFatal, errmsg(c, err)))
+		fmt.Fprintln(errw, msg(c, MsgUsage, shortUsage(a, invocation)))
+	} else if s, err := resolve(a, invocation); err != nil {
+		// should never happen if invocation originates from the parser
+		fmt.Fprintln(errw, msg(c, MsgFatal, errmsg(c, err)))
+		fmt.Fprintln(errw, msg(c, MsgUsage, shortUsage(a, invocation[:1])))
+	} else if warnings := deprecations(c, s, res.Args, opts); a.strict && len(warnings) > 0 {
+		fmt.Fprintln(errw, msg(c, MsgFatal, warnings[0]))
+		fmt.Fprintln(errw, msg(c, MsgUsage, shortUsage(a, invocation)))
 	} else {
-		action := a.Action()
-		if len(invocation) > 1 {
-			cmds := a.Commands()
-			for i, key := range invocation[1:] {
-				matched := false
-				for _, cmd := range cmds {
-					if cmd.Key() == key {
-						cmds = cmd.Commands()
-						matched = true
-						if i == len(invocation)-2 {
-							action = cmd.Action()
-						}
-						break
-					}
-				}
-				// should never happen if invocation originates from the parser
-				if !matched {
-					fmt.Fprintf(w, "fatal: invalid invocation path %v\n", invocation)
-					fmt.Fprintf(w, "usage: %v\n", shortUsage(a, invocation[:1]))
-					action = nil
-					break
-				}
+		warnw := a.warnw
+		if warnw == nil {
+			warnw = errw
+		}
+		for _, warning := range warnings {
+			fmt.Fprintln(warnw, msg(c, MsgWarning, warning))
+		}
+
+		action, cancellable := errorAction(a.Action(), a.ContextAction(), a.ErrorAction())
+		var builtin builtinAction
+		hookpath := []hookable{a}
+		for _, cmd := range s.path {
+			hookpath = append(hookpath, cmd)
+		}
+		if len(s.path) > 0 {
+			cmd := s.path[len(s.path)-1]
+			action, cancellable = errorAction(cmd.Action(), cmd.ContextAction(), cmd.ErrorAction())
+			if c, ok := cmd.(*command); ok {
+				builtin = c.builtin
 			}
 		}
-		if action != nil {
-			code = action(args, opts)
+		sigs := defaultSignals
+		if a.sigset {
+			sigs = a.sigs
+		}
+		if !cancellable {
+			sigs = nil
+		}
+		if builtin != nil {
+			code = builtin(a, res, env)
+		} else if action != nil {
+			if s.passthrough != nil {
+				ctx = context.WithValue(ctx, passthroughKey{}, res.Passthrough)
+			}
+			err := runContextAction(ctx, withHooks(action, hookpath), res.Args, opts, sigs, a.grace)
+			if !silent(err) {
+				fmt.Fprintln(errw, msg(c, MsgFatal, errmsg(c, err)))
+			}
+			code = ExitCode(err)
 		} else {
-			a.Usage(invocation, w)
+			usage(a, invocation, errw, false, env.LookupEnv)
 			code = 1
 		}
 	}
@@ -208,6 +677,8 @@ type arg struct {
 	descr    string
 	at       ValueType
 	optional bool
+	depr     *deprecation
+	compl    Completer
 }
 
 func (a arg) Key() string {
@@ -235,3 +706,47 @@ func (a arg) AsOptional() Arg {
 	a.optional = true
 	return a
 }
+
+func (a arg) Deprecated() bool {
+	return a.depr != nil
+}
+
+func (a arg) Deprecation() (notice, replacement string) {
+	return a.depr.get()
+}
+
+func (a arg) AsDeprecated(notice, replacement string) Arg {
+	a.depr = &deprecation{notice: notice, replacement: replacement}
+	return a
+}
+
+func (a arg) Completer() Completer {
+	return a.compl
+}
+
+func (a arg) WithCompleter(completer Completer) Arg {
+	a.compl = completer
+	return a
+}
+
+func helpAction(a App, res ParseResult, env Env) int {
+	s := env.Streams
+	invocation, rest := evalCommand(a, res.Args)
+	invocation = append(res.Invocation[:1:1], invocation...)
+	if len(rest) == 0 {
+		usage(a, invocation, s.Out, false, env.LookupEnv)
+		return 0
+	}
+	if len(invocation) == 1 && len(rest) == 1 {
+		for _, topic := range a.HelpTopics() {
+			if topic.Key == rest[0] {
+				fmt.Fprintln(s.Out, strings.TrimRight(topic.Text, "\n"))
+				return 0
+			}
+		}
+	}
+	c := catalog(a, env.LookupEnv)
+	fmt.Fprintln(s.Err, msg(c, MsgFatal, msg(c, MsgUnknownHelpTopic, strings.Join(rest, " "))))
+	fmt.Fprintln(s.Err, msg(c, MsgUsage, shortUsage(a, res.Invocation)))
+	return 1
+}
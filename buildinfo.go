@@ -0,0 +1,30 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+//go:build go1.18
+// +build go1.18
+
+package cli
+
+import "runtime/debug"
+
+// readBuildInfo reads the VCS revision and the Go version embedded into the binary.
+func readBuildInfo() VersionInfo {
+	var res VersionInfo
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return res
+	}
+	res.GoVersion = info.GoVersion
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			res.Revision = setting.Value
+		case "vcs.time":
+			res.Time = setting.Value
+		case "vcs.modified":
+			res.Modified = setting.Value == trueStr
+		}
+	}
+	return res
+}
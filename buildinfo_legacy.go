@@ -0,0 +1,14 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+//go:build !go1.18
+// +build !go1.18
+
+package cli
+
+import "runtime"
+
+// readBuildInfo returns the Go version only as VCS information is not embedded before Go 1.18.
+func readBuildInfo() VersionInfo {
+	return VersionInfo{GoVersion: runtime.Version()}
+}
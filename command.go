@@ -15,12 +15,37 @@ type Command interface {
 	Description() string
 	// Args returns required and optional positional arguments for this command.
 	Args() []Arg
-	// Options permitted for this command and its sub-commands.
+	// Options returns options permitted for this command and, unless local, its sub-commands.
 	Options() []Option
 	// Commands returns the set of sub-commands for this command.
 	Commands() []Command
 	// Action returns the command action when no further sub-command is specified.
 	Action() Action
+	// ContextAction returns the command action receiving a context when no further sub-command is specified.
+	ContextAction() ContextAction
+	// ErrorAction returns the command action returning an error when no further sub-command is specified.
+	ErrorAction() ErrorAction
+	// Before returns the hooks executed before the action of this command or any sub-command.
+	Before() []Hook
+	// After returns the hooks executed after the action of this command or any sub-command.
+	After() []Hook
+	// Middleware returns the middleware wrapping the execution of this command or any sub-command.
+	Middleware() []Middleware
+	// Deprecated specifies if the command is deprecated, i.e. still runs but a warning is issued.
+	Deprecated() bool
+	// Deprecation returns the deprecation notice and the suggested replacement, both may be empty.
+	Deprecation() (notice, replacement string)
+	// Hidden specifies if the command is excluded from the usage (it can still be invoked).
+	Hidden() bool
+	// Interspersed specifies if options can follow positional arguments (default), otherwise
+	// option parsing stops at the first positional argument.
+	Interspersed() bool
+	// Passthrough returns the definition of arguments following `--`, nil if not declared.
+	Passthrough() Arg
+	// Category returns the name of the category the command is listed under in the usage, empty if none.
+	Category() string
+	// Examples returns the usage examples of the command.
+	Examples() []Example
 
 	// WithShortcut adds a (shorter) command alias, e.g. `co` for `checkout`.
 	WithShortcut(shortcut string) Command
@@ -28,12 +53,48 @@ type Command interface {
 	// argument as optional permits unlimited number of further positional arguments (at least one
 	// optional argument needs to be specified in the definition for this case).
 	WithArg(arg Arg) Command
-	// WithOption adds a permitted option to the command and all sub-commands.
+	// WithOption adds a permitted option to the command and all sub-commands, see Option.AsLocal
+	// to restrict it to the command itself.
 	WithOption(opt Option) Command
 	// WithCommand adds a next-level sub-command to the command.
 	WithCommand(cmd Command) Command
 	// WithAction sets the action function for this command.
 	WithAction(action Action) Command
+	// WithContextAction sets the action function receiving a context, which is cancelled on signals, for
+	// this command, see App.RunContext. It takes precedence over the action set with WithAction.
+	WithContextAction(action ContextAction) Command
+	// WithErrorAction sets the action function returning an error for this command, see ErrorAction. It
+	// takes precedence over the actions set with WithContextAction and WithAction.
+	WithErrorAction(action ErrorAction) Command
+	// WithBefore adds a hook executed before the action of this command or any of its sub-commands,
+	// after the before hooks of its ancestors, see App.WithBefore.
+	WithBefore(hook Hook) Command
+	// WithAfter adds a hook executed after the action of this command or any of its sub-commands has
+	// succeeded, before the after hooks of its ancestors, see App.WithAfter.
+	WithAfter(hook Hook) Command
+	// WithMiddleware adds a middleware wrapping the execution of this command or any of its sub-commands
+	// within the middleware of its ancestors, see App.WithMiddleware.
+	WithMiddleware(mw Middleware) Command
+	// AsDeprecated marks the command as deprecated with an optional notice and replacement, e.g.
+	// `switch`, to be output in the warning issued when the command is used and in the usage.
+	AsDeprecated(notice, replacement string) Command
+	// AsHidden excludes the command from the usage, e.g. for internal or debugging commands.
+	AsHidden() Command
+	// WithInterspersed permits (default) or forbids options to follow positional arguments. With
+	// options not interspersed, all arguments following the first positional one are passed to the
+	// action verbatim as positional arguments, e.g. for `tool exec <cmd> [args...]`, so that these
+	// can be passed on to another command without the need for `--`.
+	WithInterspersed(interspersed bool) Command
+	// WithPassthrough declares arguments following `--` to be passed through, e.g. to a child process.
+	// These are kept separately from positional arguments and are validated against the type of arg only,
+	// see ParseArgs and PassthroughFrom.
+	WithPassthrough(arg Arg) Command
+	// WithCategory assigns the command to a named category, e.g. `Plumbing`, listed under its own
+	// heading in the usage of the parent, see Option.WithGroup.
+	WithCategory(category string) Command
+	// WithExample adds a usage example of the command given by the complete command line, e.g.
+	// `git checkout -b dev`, and its explanation, see ValidateExamples.
+	WithExample(cmdline, explanation string) Command
 }
 
 // NewCommand creates a new command to be added to an application or to another command.
@@ -49,8 +110,27 @@ type command struct {
 	opts     []Option
 	cmds     []Command
 	action   Action
+	ctxact   ContextAction
+	erract   ErrorAction
+	before   []Hook
+	after    []Hook
+	mws      []Middleware
+	depr     *deprecation
+	hidden   bool
+	nointer  bool
+	passthru Arg
+	category string
+	examples []Example
+	// builtin is executed instead of the action for built-in commands such as `completion`
+	builtin builtinAction
+	// actionName is the name of the action bound when loading from a specification
+	actionName string
 }
 
+// builtinAction defines the action of a built-in command, which in contrast to Action has access to
+// the application and the environment.
+type builtinAction func(a App, res ParseResult, env Env) int
+
 func (c *command) Key() string {
 	return c.key
 }
@@ -103,3 +183,106 @@ func (c *command) WithAction(action Action) Command {
 	c.action = action
 	return c
 }
+
+func (c *command) ContextAction() ContextAction {
+	return c.ctxact
+}
+
+func (c *command) WithContextAction(action ContextAction) Command {
+	c.ctxact = action
+	return c
+}
+
+func (c *command) Before() []Hook {
+	return c.before
+}
+
+func (c *command) WithBefore(hook Hook) Command {
+	c.before = append(c.before, hook)
+	return c
+}
+
+func (c *command) After() []Hook {
+	return c.after
+}
+
+func (c *command) WithAfter(hook Hook) Command {
+	c.after = append(c.after, hook)
+	return c
+}
+
+func (c *command) Middleware() []Middleware {
+	return c.mws
+}
+
+func (c *command) WithMiddleware(mw Middleware) Command {
+	c.mws = append(c.mws, mw)
+	return c
+}
+
+func (c *command) ErrorAction() ErrorAction {
+	return c.erract
+}
+
+func (c *command) WithErrorAction(action ErrorAction) Command {
+	c.erract = action
+	return c
+}
+
+func (c *command) Deprecated() bool {
+	return c.depr != nil
+}
+
+func (c *command) Deprecation() (notice, replacement string) {
+	return c.depr.get()
+}
+
+func (c *command) AsDeprecated(notice, replacement string) Command {
+	c.depr = &deprecation{notice: notice, replacement: replacement}
+	return c
+}
+
+func (c *command) Hidden() bool {
+	return c.hidden
+}
+
+func (c *command) AsHidden() Command {
+	c.hidden = true
+	return c
+}
+
+func (c *command) Interspersed() bool {
+	return !c.nointer
+}
+
+func (c *command) WithInterspersed(interspersed bool) Command {
+	c.nointer = !interspersed
+	return c
+}
+
+func (c *command) Passthrough() Arg {
+	return c.passthru
+}
+
+func (c *command) WithPassthrough(arg Arg) Command {
+	c.passthru = arg
+	return c
+}
+
+func (c *command) Category() string {
+	return c.category
+}
+
+func (c *command) WithCategory(category string) Command {
+	c.category = category
+	return c
+}
+
+func (c *command) Examples() []Example {
+	return c.examples
+}
+
+func (c *command) WithExample(cmdline, explanation string) Command {
+	c.examples = append(c.examples, Example{Cmdline: cmdline, Explanation: explanation})
+	return c
+}
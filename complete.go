@@ -0,0 +1,283 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	completeKey = "__complete"
+	dynamicKey  = "dynamic"
+)
+
+// Completer defines a function returning completion candidates for an option or argument value given
+// the value prefix typed so far and the partially parsed command line. Candidates not starting with the
+// prefix are ignored. Returning DirectiveFiles or DirectiveDirs as a candidate delegates completion to
+// the shell, see FileCompleter and DirCompleter.
+type Completer func(prefix string, partial ParseResult) []string
+
+// FileCompleter completes file names.
+func FileCompleter(prefix string, partial ParseResult) []string {
+	return []string{string(DirectiveFiles)}
+}
+
+// DirCompleter completes directory names.
+func DirCompleter(prefix string, partial ParseResult) []string {
+	return []string{string(DirectiveDirs)}
+}
+
+// Directive instructs the shell how to handle the completion candidates.
+type Directive string
+
+// Directive constants output as the last line of the `__complete` command.
+const (
+	// DirectiveDefault completes the candidates followed by a space.
+	DirectiveDefault Directive = ":default"
+	// DirectiveNoSpace completes the candidates without a space, e.g. for `--key=`.
+	DirectiveNoSpace Directive = ":nospace"
+	// DirectiveFiles ignores the candidates and lets the shell complete file names.
+	DirectiveFiles Directive = ":files"
+	// DirectiveDirs ignores the candidates and lets the shell complete directory names.
+	DirectiveDirs Directive = ":dirs"
+)
+
+// Complete computes the completion candidates for the last element of the original application
+// arguments, the possibly empty word being completed, e.g. `[gitc checkout --fallback=ma]`, using the
+// same logic as Parse. Sub-commands, options and values of options and arguments with completers are
+// completed. Hidden commands and options are not completed.
+func Complete(a App, appargs []string) (candidates []string, directive Directive) {
+	if len(appargs) < 2 {
+		return nil, DirectiveDefault
+	}
+	prefix := appargs[len(appargs)-1]
+
+	invocation, argsAndOpts := evalCommand(a, appargs[1:len(appargs)-1])
+	partial := ParseResult{Invocation: append([]string{appname(appargs[0])}, invocation...)}
+	// never fails for the invocation path computed by evalCommand
+	sc, _ := resolve(a, partial.Invocation)
+	permitted := sc.permitted()
+	// errors are expected for partial command lines and ignored
+	partial.Args, partial.Passthrough, partial.Opts, _, _ = splitArgsAndOpts(argsAndOpts, permitted, sc.interspersed, false, false)
+
+	dashdash := false
+	for i, word := range argsAndOpts {
+		// `--` as the value of a dangling option does not start the passthrough arguments
+		if word == "--" && (i == 0 || danglingOpt(argsAndOpts[i-1], permitted) == nil) {
+			dashdash = true
+			break
+		}
+	}
+	stopped := dashdash || (!sc.interspersed && len(partial.Args) > 0)
+
+	if !stopped && len(argsAndOpts) > 0 {
+		if opt := danglingOpt(argsAndOpts[len(argsAndOpts)-1], permitted); opt != nil {
+			return completeWith(opt.Completer(), prefix, "", partial)
+		}
+	}
+
+	if !stopped && strings.HasPrefix(prefix, "--") && strings.Contains(prefix, "=") {
+		parts := strings.SplitN(prefix, "=", 2)
+		for _, opt := range permitted {
+			if "--"+opt.Key() == parts[0] {
+				return completeWith(opt.Completer(), parts[1], parts[0]+"=", partial)
+			}
+		}
+		return nil, DirectiveDefault
+	}
+
+	if !stopped && strings.HasPrefix(prefix, "-") {
+		directive = DirectiveNoSpace
+		words := []string{"--" + helpKey}
+		for _, opt := range visibleOpts(permitted) {
+			words = append(words, complword(opt))
+			if opt.CharKey() != rune(0) && len(prefix) <= 2 {
+				words = append(words, "-"+string(opt.CharKey()))
+			}
+		}
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				candidates = append(candidates, word)
+				if !strings.HasSuffix(word, "=") {
+					directive = DirectiveDefault
+				}
+			}
+		}
+		return candidates, directive
+	}
+
+	if dashdash && sc.passthrough != nil {
+		return completeWith(sc.passthrough.Completer(), prefix, "", partial)
+	}
+
+	if len(argsAndOpts) == 0 {
+		for _, cmd := range visibleCmds(sc.cmds) {
+			if strings.HasPrefix(cmd.Key(), prefix) {
+				candidates = append(candidates, cmd.Key())
+			}
+		}
+	}
+
+	var arg Arg
+	if n := len(partial.Args); n < len(sc.args) {
+		arg = sc.args[n]
+	} else if len(sc.args) > 0 && sc.args[len(sc.args)-1].Optional() {
+		arg = sc.args[len(sc.args)-1]
+	}
+	if arg == nil || arg.Completer() == nil {
+		if len(candidates) > 0 {
+			return candidates, DirectiveDefault
+		}
+		return nil, DirectiveFiles
+	}
+	argcandidates, directive := completeWith(arg.Completer(), prefix, "", partial)
+	return append(candidates, argcandidates...), directive
+}
+
+// danglingOpt returns the non-boolean option given by its char key at the terminal position of word,
+// which expects its value in the next word.
+func danglingOpt(word string, permitted []Option) Option {
+	if len(word) < 2 || word[0] != '-' || word[1] == '-' {
+		return nil
+	}
+	chars := []rune(word)
+	char := chars[len(chars)-1]
+	for _, opt := range permitted {
+		if opt.CharKey() == char && opt.Type() != TypeBool {
+			return opt
+		}
+	}
+	return nil
+}
+
+func completeWith(completer Completer, prefix, valueprefix string, partial ParseResult) ([]string, Directive) {
+	if completer == nil {
+		return nil, DirectiveFiles
+	}
+	var candidates []string
+	for _, candidate := range completer(prefix, partial) {
+		switch Directive(candidate) {
+		case DirectiveFiles, DirectiveDirs:
+			return nil, Directive(candidate)
+		}
+		if strings.HasPrefix(candidate, prefix) {
+			candidates = append(candidates, valueprefix+candidate)
+		}
+	}
+	return candidates, DirectiveDefault
+}
+
+func completeAction(a App, res ParseResult, env Env) int {
+	s := env.Streams
+	candidates, directive := Complete(a, append([]string{res.Invocation[0]}, res.Passthrough...))
+	for _, candidate := range candidates {
+		fmt.Fprintln(s.Out, candidate)
+	}
+	fmt.Fprintln(s.Out, directive)
+	return 0
+}
+
+// DynamicCompletion outputs a completion script for the given shell (bash, zsh, fish or powershell),
+// which delegates the computation of candidates to the hidden `__complete` command of the application
+// named appname, see App.WithCompletionCommand and `completion --dynamic <shell>`. The command is
+// invoked as `appname __complete -- words...` with the words of the command line up to the one being
+// completed and outputs the candidates one per line followed by a Directive.
+func DynamicCompletion(appname, shell string, w io.Writer) error {
+	fname := "_" + complid(appname, nil)
+	switch shell {
+	case ShellBash:
+		fmt.Fprintf(w, bashDynamic, appname, fname, shquote(appname), completeKey, fname, shquote(appname))
+	case ShellZsh:
+		fmt.Fprintf(w, zshDynamic, appname, fname, shquote(appname), completeKey, fname, appname)
+	case ShellFish:
+		fmt.Fprintf(w, fishDynamic, appname, fname, shquote(appname), completeKey, appname, fname)
+	case ShellPowerShell:
+		quoted := "'" + strings.Replace(appname, "'", "''", -1) + "'"
+		fmt.Fprintf(w, powershellDynamic, appname, quoted, quoted, completeKey)
+	default:
+		return errorf(MsgUnsupportedShell, shell)
+	}
+	return nil
+}
+
+const bashDynamic = `# bash completion for %s
+%s() {
+    local line="${COMP_LINE:0:COMP_POINT}" cur="${COMP_WORDS[COMP_CWORD]}" word directive
+    local -a words lines
+    read -r -a words <<< "${line}"
+    if [[ -z "${line}" || "${line}" == *[[:space:]] ]]; then
+        words+=("")
+    fi
+    word="${words[${#words[@]}-1]}"
+    mapfile -t lines < <(%s %s -- "${words[@]:1}" 2>/dev/null)
+    (( ${#lines[@]} )) || return
+    directive="${lines[${#lines[@]}-1]}"
+    unset 'lines[${#lines[@]}-1]'
+    case "${directive}" in
+        :files) COMPREPLY=($(compgen -f -- "${cur}")); return ;;
+        :dirs) COMPREPLY=($(compgen -d -- "${cur}")); return ;;
+        :nospace) compopt -o nospace ;;
+    esac
+    # bash splits words at =, candidates need to replace the current bash word only
+    COMPREPLY=("${lines[@]#"${word%%"${cur}"}"}")
+}
+complete -F %s %s
+`
+
+const zshDynamic = `#compdef %s
+
+%s() {
+    local -a lines
+    local directive
+    lines=("${(@f)$(%s %s -- "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    directive="${lines[-1]}"
+    lines=("${(@)lines[1,-2]}")
+    case "${directive}" in
+        :files) _files ;;
+        :dirs) _files -/ ;;
+        :nospace) compadd -S '' -- "${(@)lines}" ;;
+        *) compadd -- "${(@)lines}" ;;
+    esac
+}
+
+compdef %s %s
+`
+
+const fishDynamic = `# fish completion for %s
+function %s
+    set -l tokens (commandline -opc) (commandline -ct)
+    set -l lines (%s %s -- $tokens[2..-1] 2>/dev/null)
+    test (count $lines) -gt 0; or return
+    switch "$lines[-1]"
+        case :files
+            __fish_complete_path (commandline -ct)
+        case :dirs
+            __fish_complete_directories (commandline -ct)
+        case '*'
+            set -e lines[-1]
+            printf '%%s\n' $lines
+    end
+end
+
+complete -c %s -f -a '(%s)'
+`
+
+const powershellDynamic = `# powershell completion for %s
+Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 |
+        Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+    $lines = @(& %s %s -- @words "$wordToComplete" 2>$null)
+    if ($lines.Count -eq 0) { return }
+    switch ($lines[-1]) {
+        ':files' { return }
+        ':dirs' { return }
+    }
+    $lines | Select-Object -SkipLast 1 | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
@@ -0,0 +1,101 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupCompleteApp() cli.App {
+	branches := func(prefix string, partial cli.ParseResult) []string {
+		return []string{"master", "main", "dev"}
+	}
+	co := cli.NewCommand("checkout", "Check out a branch or revision").
+		WithShortcut("co").
+		WithArg(cli.NewArg("revision", "branch or revision to checkout").WithCompleter(branches)).
+		WithArg(cli.NewArg("path", "path to checkout").WithCompleter(cli.DirCompleter)).
+		WithOption(cli.NewOption("branch", "Create branch if missing").WithChar('b').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("fallback", "Fallback branch").WithChar('f').WithCompleter(branches)).
+		WithOption(cli.NewOption("trace", "Trace").WithType(cli.TypeBool).AsHidden())
+
+	remotes := func(prefix string, partial cli.ParseResult) []string {
+		return []string{fmt.Sprintf("%v", partial.Invocation), fmt.Sprintf("%v", partial.Opts)}
+	}
+	rm := cli.NewCommand("rm", "Remove a remote").
+		WithArg(cli.NewArg("remote", "remote to remove").WithCompleter(remotes))
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithCommand(cli.NewCommand("remote", "Work with remotes").WithCommand(rm)).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithCompletionCommand()
+}
+
+func assertComplete(t *testing.T, expected string, appargs ...string) {
+	candidates, directive := cli.Complete(setupCompleteApp(), append([]string{"./git"}, appargs...))
+	actual := fmt.Sprintf("%v %s", candidates, directive)
+	if actual != expected {
+		t.Errorf("completion of %v: expected '%v', found '%v'", appargs, expected, actual)
+	}
+}
+
+func TestComplete_SubCommands_ok(t *testing.T) {
+	assertComplete(t, "[checkout completion] :default", "c")
+	assertComplete(t, "[rm] :default", "remote", "")
+}
+
+func TestComplete_Options_ok(t *testing.T) {
+	assertComplete(t, "[--help --verbose -v --branch -b --fallback= -f] :default", "co", "-")
+	assertComplete(t, "[--fallback=] :nospace", "co", "--f")
+}
+
+func TestComplete_OptionValues_ok(t *testing.T) {
+	assertComplete(t, "[--fallback=master --fallback=main] :default", "co", "--fallback=ma")
+	assertComplete(t, "[master main dev] :default", "checkout", "-bf", "")
+}
+
+func TestComplete_ArgValues_ok(t *testing.T) {
+	assertComplete(t, "[dev] :default", "co", "-v", "d")
+	assertComplete(t, "[] :dirs", "co", "-f", "dev", "master", "")
+	assertComplete(t, "[[git remote rm] map[verbose:true]] :default", "remote", "rm", "-v", "")
+}
+
+func TestApp_Run_HiddenCompleteCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupCompleteApp().Run([]string{"./git", "__complete", "--", "co", "--fallback=m"}, w)
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "--fallback=master\n--fallback=main\n:default\n", w.str)
+}
+
+func TestDynamicCompletion_AllShells_ok(t *testing.T) {
+	for _, shell := range []string{cli.ShellBash, cli.ShellZsh, cli.ShellFish, cli.ShellPowerShell} {
+		w := &stringwriter{}
+		if err := cli.DynamicCompletion("git", shell, w); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(w.str, "'git' __complete --") {
+			t.Errorf("expected %s completion to delegate to __complete, found: %v", shell, w.str)
+		}
+	}
+}
+
+func TestApp_Run_CompletionCommandDynamic_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupCompleteApp().Run([]string{"./git", "completion", "bash"}, w)
+	assertAppRunOk(t, 0, code)
+	if strings.Contains(w.str, "__complete") {
+		t.Errorf("expected static completion by default, found: %v", w.str)
+	}
+
+	w = &stringwriter{}
+	code = setupCompleteApp().Run([]string{"./git", "completion", "--dynamic", "bash"}, w)
+	assertAppRunOk(t, 0, code)
+	if !strings.Contains(w.str, "'git' __complete --") {
+		t.Errorf("expected dynamic completion with --dynamic, found: %v", w.str)
+	}
+}
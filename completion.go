@@ -0,0 +1,319 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Shells supported for completion scripts.
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// complnode captures completion candidates for a command in the command tree.
+type complnode struct {
+	// id is the identifier of the command in the scripts, e.g. gitc_remote_add
+	id string
+	// children maps sub-command keys and shortcuts to the ids of sub-commands
+	children map[string]string
+	cmds     []Command
+	opts     []Option
+}
+
+// complnodes walks the command tree collecting completion candidates, excluding hidden commands and
+// options, for every command, root first.
+func complnodes(a App, appname string) []complnode {
+	var res []complnode
+	var walk func(invocation []string)
+	walk = func(invocation []string) {
+		// never fails for the invocation path computed from the tree
+		sc, _ := resolve(a, append([]string{appname}, invocation...))
+		node := complnode{
+			id:       complid(appname, invocation),
+			children: make(map[string]string),
+			cmds:     visibleCmds(sc.cmds),
+			opts:     visibleOpts(sc.permitted()),
+		}
+		for _, cmd := range node.cmds {
+			id := complid(appname, append(invocation, cmd.Key()))
+			node.children[cmd.Key()] = id
+			if cmd.Shortcut() != "" {
+				node.children[cmd.Shortcut()] = id
+			}
+		}
+		res = append(res, node)
+		for _, cmd := range node.cmds {
+			walk(append(append([]string{}, invocation...), cmd.Key()))
+		}
+	}
+	walk(nil)
+	return res
+}
+
+func complid(appname string, invocation []string) string {
+	id := strings.Join(append([]string{appname}, invocation...), "_")
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, id)
+}
+
+// complword returns the completion candidate for the complete key of an option.
+func complword(opt Option) string {
+	if opt.Type() == TypeBool {
+		return "--" + opt.Key()
+	}
+	return "--" + opt.Key() + "="
+}
+
+// sortedkeys returns the keys of the children map in the lexical order for a deterministic output.
+func sortedkeys(children map[string]string) []string {
+	var res []string
+	for key := range children {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Completion outputs a static completion script for the given shell (bash, zsh, fish or powershell)
+// covering sub-commands, their shortcuts and options of the application named appname. Hidden commands
+// and options are not completed.
+func Completion(a App, appname, shell string, w io.Writer) error {
+	nodes := complnodes(a, appname)
+	switch shell {
+	case ShellBash:
+		bashCompletion(appname, nodes, w)
+	case ShellZsh:
+		zshCompletion(appname, nodes, w)
+	case ShellFish:
+		fishCompletion(appname, nodes, w)
+	case ShellPowerShell:
+		powershellCompletion(appname, nodes, w)
+	default:
+		return errorf(MsgUnsupportedShell, shell)
+	}
+	return nil
+}
+
+func shquote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func bashCompletion(appname string, nodes []complnode, w io.Writer) {
+	fname := "_" + complid(appname, nil)
+	fmt.Fprintf(w, "# bash completion for %s\n", appname)
+	fmt.Fprintf(w, "%s() {\n", fname)
+	fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" cmd=%s words i\n", shquote(nodes[0].id))
+	fmt.Fprintln(w, "    for ((i = 1; i < COMP_CWORD; i++)); do")
+	fmt.Fprintln(w, "        case \"${cmd}:${COMP_WORDS[i]}\" in")
+	for _, node := range nodes {
+		for _, key := range sortedkeys(node.children) {
+			fmt.Fprintf(w, "            %s) cmd=%s ;;\n", shquote(node.id+":"+key), shquote(node.children[key]))
+		}
+	}
+	fmt.Fprintln(w, "            *) break ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w, "    case \"${cmd}\" in")
+	for _, node := range nodes {
+		var words []string
+		for _, cmd := range node.cmds {
+			words = append(words, cmd.Key())
+		}
+		for _, opt := range node.opts {
+			words = append(words, complword(opt))
+			if opt.CharKey() != rune(0) {
+				words = append(words, "-"+string(opt.CharKey()))
+			}
+		}
+		words = append(words, "--"+helpKey, "-"+string(helpChar))
+		fmt.Fprintf(w, "        %s) words=%s ;;\n", shquote(node.id), shquote(strings.Join(words, " ")))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "    COMPREPLY=($(compgen -W \"${words}\" -- \"${cur}\"))")
+	fmt.Fprintln(w, "    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *= ]]; then")
+	fmt.Fprintln(w, "        compopt -o nospace")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fname, shquote(appname))
+}
+
+func zshCompletion(appname string, nodes []complnode, w io.Writer) {
+	item := func(name, descr string) string {
+		return shquote(strings.Replace(name, ":", `\:`, -1) + ":" + descr)
+	}
+	fname := "_" + complid(appname, nil)
+	fmt.Fprintf(w, "#compdef %s\n\n", appname)
+	fmt.Fprintf(w, "%s() {\n", fname)
+	fmt.Fprintf(w, "    local cmd=%s i\n", shquote(nodes[0].id))
+	fmt.Fprintln(w, "    local -a cmds flags valued")
+	fmt.Fprintln(w, "    for ((i = 2; i < CURRENT; i++)); do")
+	fmt.Fprintln(w, "        case \"${cmd}:${words[i]}\" in")
+	for _, node := range nodes {
+		for _, key := range sortedkeys(node.children) {
+			fmt.Fprintf(w, "            %s) cmd=%s ;;\n", shquote(node.id+":"+key), shquote(node.children[key]))
+		}
+	}
+	fmt.Fprintln(w, "            *) break ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w, "    case \"${cmd}\" in")
+	for _, node := range nodes {
+		var cmds, flags, valued []string
+		for _, cmd := range node.cmds {
+			cmds = append(cmds, item(cmd.Key(), cmd.Description()))
+		}
+		for _, opt := range node.opts {
+			if opt.Type() == TypeBool {
+				flags = append(flags, item(complword(opt), opt.Description()))
+			} else {
+				valued = append(valued, item(complword(opt), opt.Description()))
+			}
+			if opt.CharKey() != rune(0) {
+				flags = append(flags, item("-"+string(opt.CharKey()), opt.Description()))
+			}
+		}
+		flags = append(flags, item("--"+helpKey, "Show usage"), item("-"+string(helpChar), "Show usage"))
+		fmt.Fprintf(w, "        %s)\n", shquote(node.id))
+		fmt.Fprintf(w, "            cmds=(%s)\n", strings.Join(cmds, " "))
+		fmt.Fprintf(w, "            flags=(%s)\n", strings.Join(flags, " "))
+		fmt.Fprintf(w, "            valued=(%s)\n", strings.Join(valued, " "))
+		fmt.Fprintln(w, "            ;;")
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "    if [[ \"${words[CURRENT]}\" == -* ]]; then")
+	fmt.Fprintln(w, "        _describe -t options 'option' flags")
+	fmt.Fprintln(w, "        _describe -t options 'option' valued -S ''")
+	fmt.Fprintln(w, "    elif (( ${#cmds} )); then")
+	fmt.Fprintln(w, "        _describe -t commands 'command' cmds")
+	fmt.Fprintln(w, "    else")
+	fmt.Fprintln(w, "        _files")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "\ncompdef %s %s\n", fname, appname)
+}
+
+func fishCompletion(appname string, nodes []complnode, w io.Writer) {
+	quote := func(s string) string {
+		return "'" + strings.Replace(strings.Replace(s, `\`, `\\`, -1), "'", `\'`, -1) + "'"
+	}
+	fname := "__" + complid(appname, nil) + "_cmd"
+	fmt.Fprintf(w, "# fish completion for %s\n", appname)
+	fmt.Fprintf(w, "function %s\n", fname)
+	fmt.Fprintln(w, "    set -l tokens (commandline -opc)")
+	fmt.Fprintf(w, "    set -l cmd %s\n", quote(nodes[0].id))
+	fmt.Fprintln(w, "    for token in $tokens[2..-1]")
+	fmt.Fprintln(w, "        switch \"$cmd:$token\"")
+	for _, node := range nodes {
+		for _, key := range sortedkeys(node.children) {
+			fmt.Fprintf(w, "            case %s\n", quote(node.id+":"+key))
+			fmt.Fprintf(w, "                set cmd %s\n", quote(node.children[key]))
+		}
+	}
+	fmt.Fprintln(w, "            case '*'")
+	fmt.Fprintln(w, "                break")
+	fmt.Fprintln(w, "        end")
+	fmt.Fprintln(w, "    end")
+	fmt.Fprintln(w, "    echo $cmd")
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	for _, node := range nodes {
+		cond := quote("test (" + fname + ") = " + node.id)
+		for _, cmd := range node.cmds {
+			fmt.Fprintf(w, "complete -c %s -n %s -f -a %s -d %s\n", appname, cond, quote(cmd.Key()), quote(cmd.Description()))
+		}
+		for _, opt := range node.opts {
+			line := fmt.Sprintf("complete -c %s -n %s -l %s", appname, cond, quote(opt.Key()))
+			if opt.CharKey() != rune(0) {
+				line += " -s " + quote(string(opt.CharKey()))
+			}
+			if opt.Type() != TypeBool {
+				line += " -r"
+			}
+			fmt.Fprintf(w, "%s -d %s\n", line, quote(opt.Description()))
+		}
+		fmt.Fprintf(w, "complete -c %s -n %s -l help -s h -d 'Show usage'\n", appname, cond)
+	}
+}
+
+func powershellCompletion(appname string, nodes []complnode, w io.Writer) {
+	quote := func(s string) string {
+		return "'" + strings.Replace(s, "'", "''", -1) + "'"
+	}
+	candidate := func(word, descr string) string {
+		if descr == "" {
+			descr = word
+		}
+		return fmt.Sprintf("@{ Word = %s; Descr = %s }", quote(word), quote(descr))
+	}
+	fmt.Fprintf(w, "# powershell completion for %s\n", appname)
+	fmt.Fprintf(w, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", quote(appname))
+	fmt.Fprintln(w, "    param($wordToComplete, $commandAst, $cursorPosition)")
+	fmt.Fprintf(w, "    $cmd = %s\n", quote(nodes[0].id))
+	fmt.Fprintln(w, "    $elements = $commandAst.CommandElements | Select-Object -Skip 1 |")
+	fmt.Fprintln(w, "        Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() }")
+	fmt.Fprintln(w, "    foreach ($element in $elements) {")
+	fmt.Fprintln(w, "        $next = switch (\"${cmd}:${element}\") {")
+	for _, node := range nodes {
+		for _, key := range sortedkeys(node.children) {
+			fmt.Fprintf(w, "            %s { %s }\n", quote(node.id+":"+key), quote(node.children[key]))
+		}
+	}
+	fmt.Fprintln(w, "            default { $null }")
+	fmt.Fprintln(w, "        }")
+	fmt.Fprintln(w, "        if (-not $next) { break }")
+	fmt.Fprintln(w, "        $cmd = $next")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates = switch ($cmd) {")
+	for _, node := range nodes {
+		var candidates []string
+		for _, cmd := range node.cmds {
+			candidates = append(candidates, candidate(cmd.Key(), cmd.Description()))
+		}
+		for _, opt := range node.opts {
+			candidates = append(candidates, candidate(complword(opt), opt.Description()))
+			if opt.CharKey() != rune(0) {
+				candidates = append(candidates, candidate("-"+string(opt.CharKey()), opt.Description()))
+			}
+		}
+		candidates = append(candidates, candidate("--help", "Show usage"), candidate("-h", "Show usage"))
+		fmt.Fprintf(w, "        %s {\n", quote(node.id))
+		for _, c := range candidates {
+			fmt.Fprintf(w, "            %s\n", c)
+		}
+		fmt.Fprintln(w, "        }")
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates | Where-Object { $_.Word -like \"$wordToComplete*\" } | ForEach-Object {")
+	fmt.Fprintln(w, "        [System.Management.Automation.CompletionResult]::new($_.Word, $_.Word, 'ParameterValue', $_.Descr)")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "}")
+}
+
+func completionAction(a App, res ParseResult, env Env) int {
+	s := env.Streams
+	completion := func(appname, shell string, w io.Writer) error {
+		return Completion(a, appname, shell, w)
+	}
+	if _, dynamic := res.Opts[dynamicKey]; dynamic {
+		completion = DynamicCompletion
+	}
+	if err := completion(res.Invocation[0], res.Args[0], s.Out); err != nil {
+		c := catalog(a, env.LookupEnv)
+		fmt.Fprintln(s.Err, msg(c, MsgFatal, err))
+		fmt.Fprintln(s.Err, msg(c, MsgUsage, shortUsage(a, res.Invocation)))
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupCompletionApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch or revision").
+		WithShortcut("co").
+		WithArg(cli.NewArg("revision", "branch or revision to checkout")).
+		WithOption(cli.NewOption("branch", "Create branch if missing").WithChar('b').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("fallback", "Fall back to 'master'"))
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithCommand(cli.NewCommand("debug", "Debug").AsHidden()).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithCompletionCommand()
+}
+
+func TestCompletion_Bash_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.Completion(setupCompletionApp(), "foo", cli.ShellBash, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# bash completion for foo
+_foo() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" cmd='foo' words i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${cmd}:${COMP_WORDS[i]}" in
+            'foo:checkout') cmd='foo_checkout' ;;
+            'foo:co') cmd='foo_checkout' ;;
+            'foo:completion') cmd='foo_completion' ;;
+            *) break ;;
+        esac
+    done
+    case "${cmd}" in
+        'foo') words='checkout completion --verbose -v --help -h' ;;
+        'foo_checkout') words='--verbose -v --branch -b --fallback= --help -h' ;;
+        'foo_completion') words='--verbose -v --dynamic --help -h' ;;
+    esac
+    COMPREPLY=($(compgen -W "${words}" -- "${cur}"))
+    if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *= ]]; then
+        compopt -o nospace
+    fi
+}
+complete -o default -F _foo 'foo'
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestCompletion_AllShells_QuotedDescriptions_ok(t *testing.T) {
+	expected := map[string]string{
+		cli.ShellZsh:        `valued=('--fallback=:Fall back to '\''master'\''')`,
+		cli.ShellFish:       `-l 'fallback' -r -d 'Fall back to \'master\''`,
+		cli.ShellPowerShell: `@{ Word = '--fallback='; Descr = 'Fall back to ''master''' }`,
+	}
+	for shell, fragment := range expected {
+		w := &stringwriter{}
+		if err := cli.Completion(setupCompletionApp(), "foo", shell, w); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(w.str, fragment) {
+			t.Errorf("expected %s completion to contain %v, found: %v", shell, fragment, w.str)
+		}
+		if strings.Contains(w.str, "debug") {
+			t.Errorf("expected %s completion to exclude hidden commands, found: %v", shell, w.str)
+		}
+	}
+}
+
+func TestApp_Run_CompletionCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupCompletionApp().Run([]string{"./foo", "completion", "zsh"}, w)
+	assertAppRunOk(t, 0, code)
+	if !strings.HasPrefix(w.str, "#compdef foo\n") {
+		t.Errorf("expected zsh completion, found: %v", w.str)
+	}
+}
+
+func TestApp_Run_CompletionCommandUnsupportedShell_error(t *testing.T) {
+	w := &stringwriter{}
+	code := setupCompletionApp().Run([]string{"./foo", "completion", "tcsh"}, w)
+	assertAppRunOk(t, 1, code)
+	expected := `fatal: unsupported shell tcsh
+usage: foo completion [--verbose] [--dynamic] <shell>
+`
+	assertAppUsageOk(t, expected, w.str)
+}
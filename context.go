@@ -0,0 +1,83 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ContextAction defines a function type to be executed for an application or a command same as
+// Action, but receiving a context, which is cancelled when the application receives one of the
+// configured signals (SIGINT and SIGTERM by default), see App.WithSignals and App.RunContext.
+type ContextAction func(ctx context.Context, args []string, options map[string]string) int
+
+type passthroughKey struct{}
+
+// PassthroughFrom returns the arguments following `--` for use in a ContextAction or ErrorAction of a
+// command declaring passthrough arguments, see Command.WithPassthrough. Plain actions receive positional
+// arguments only.
+func PassthroughFrom(ctx context.Context) []string {
+	passthrough, _ := ctx.Value(passthroughKey{}).([]string)
+	return passthrough
+}
+
+// defaultSignals lists the signals cancelling the context of a ContextAction by default.
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// runContextAction runs the action with a context cancelled on the first of the given signals. The
+// error of the action is returned unless a signal is received, in which case an ExitError with the
+// conventional exit code of 128 plus the signal number is returned after the action has finished, after
+// the grace period has expired (unless zero) or immediately on the second signal.
+func runContextAction(ctx context.Context, action ErrorAction, args []string, opts map[string]string, sigs []os.Signal, grace time.Duration) error {
+	if len(sigs) == 0 {
+		return action(ctx, args, opts)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sigch := make(chan os.Signal, 2)
+	signal.Notify(sigch, sigs...)
+	defer signal.Stop(sigch)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- action(ctx, args, opts)
+	}()
+
+	var received os.Signal
+	var timeout <-chan time.Time
+	for {
+		select {
+		case err := <-done:
+			if received != nil {
+				return exitError(signalCode(received))
+			}
+			return err
+		case sig := <-sigch:
+			if received != nil {
+				// second signal: do not wait for the action any longer
+				return exitError(signalCode(received))
+			}
+			received = sig
+			cancel()
+			if grace > 0 {
+				timeout = time.After(grace)
+			}
+		case <-timeout:
+			return exitError(signalCode(received))
+		}
+	}
+}
+
+// signalCode returns the conventional exit code for termination by a signal, e.g. 130 for SIGINT.
+func signalCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+//go:build !windows
+// +build !windows
+
+package cli_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/teris-io/cli"
+)
+
+type ctxkey string
+
+// signalSelf sends the signal to the test process from within an action, which runs on a goroutine of
+// its own, hence the error is reported with t.Error and the action is expected to return on false.
+func signalSelf(t *testing.T, sig syscall.Signal) bool {
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		t.Error(err)
+		return false
+	}
+	return true
+}
+
+func TestApp_RunContext_PassesContext_ok(t *testing.T) {
+	a := cli.New("sleep").
+		WithCommand(cli.NewCommand("run", "Run").
+			WithArg(cli.NewArg("n", "number").WithType(cli.TypeInt)).
+			WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+				if ctx.Value(ctxkey("key")) != "value" || len(args) != 1 || args[0] != "5" {
+					return 1
+				}
+				return 5
+			}))
+	ctx := context.WithValue(context.Background(), ctxkey("key"), "value")
+	code := a.RunContext(ctx, []string{"./sleep", "run", "5"}, &stringwriter{})
+	assertAppRunOk(t, 5, code)
+}
+
+func TestApp_RunContext_ParentCancelled_ok(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := cli.New("sleep").
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			cancel()
+			<-ctx.Done()
+			return 3
+		})
+	code := a.RunContext(ctx, []string{"./sleep"}, &stringwriter{})
+	assertAppRunOk(t, 3, code)
+}
+
+func TestApp_Run_SignalCancelsContext_ok(t *testing.T) {
+	for sig, expected := range map[syscall.Signal]int{syscall.SIGINT: 130, syscall.SIGTERM: 143} {
+		sig := sig
+		a := cli.New("sleep").
+			WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+				if !signalSelf(t, sig) {
+					return 0
+				}
+				<-ctx.Done()
+				return 0
+			})
+		code := a.Run([]string{"./sleep"}, &stringwriter{})
+		assertAppRunOk(t, expected, code)
+	}
+}
+
+func TestApp_Run_SignalGracePeriodExpires_ok(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	a := cli.New("sleep").
+		WithGracePeriod(20 * time.Millisecond).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			if !signalSelf(t, syscall.SIGINT) {
+				return 0
+			}
+			<-block
+			return 0
+		})
+	code := a.Run([]string{"./sleep"}, &stringwriter{})
+	assertAppRunOk(t, 130, code)
+}
+
+func TestApp_Run_SecondSignalForcesExit_ok(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	a := cli.New("sleep").
+		WithSignals(syscall.SIGUSR1).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			if !signalSelf(t, syscall.SIGUSR1) {
+				return 0
+			}
+			<-ctx.Done()
+			if !signalSelf(t, syscall.SIGUSR1) {
+				return 0
+			}
+			<-block
+			return 0
+		})
+	code := a.Run([]string{"./sleep"}, &stringwriter{})
+	assertAppRunOk(t, 128+int(syscall.SIGUSR1), code)
+}
+
+func TestApp_Run_ContextActionTakesPrecedence_ok(t *testing.T) {
+	a := cli.New("sleep").
+		WithAction(func(args []string, options map[string]string) int {
+			return 1
+		}).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			return 2
+		})
+	code := a.Run([]string{"./sleep"}, &stringwriter{})
+	assertAppRunOk(t, 2, code)
+}
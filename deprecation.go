@@ -0,0 +1,68 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+type deprecation struct {
+	notice      string
+	replacement string
+}
+
+func (d *deprecation) get() (notice, replacement string) {
+	if d == nil {
+		return "", ""
+	}
+	return d.notice, d.replacement
+}
+
+type deprecatable interface {
+	Deprecated() bool
+	Deprecation() (notice, replacement string)
+}
+
+// deprecations lists the deprecated commands, options and arguments used in the invocation given
+// by its resolved scope, the positional arguments and the options, with messages from the catalog.
+func deprecations(c Catalog, s *scope, args []string, opts map[string]string) []string {
+	var res []string
+	for _, cmd := range s.path {
+		if cmd.Deprecated() {
+			res = append(res, deprecationstr(c, msg(c, MsgDeprecatedCommand, cmd.Key()), cmd))
+		}
+	}
+
+	for _, opt := range s.permitted() {
+		if _, ok := opts[opt.Key()]; ok && opt.Deprecated() {
+			res = append(res, deprecationstr(c, msg(c, MsgDeprecatedOption, opt.Key()), opt))
+		}
+	}
+
+	for i, arg := range s.args {
+		if i < len(args) && arg.Deprecated() {
+			res = append(res, deprecationstr(c, msg(c, MsgDeprecatedArgument, arg.Key()), arg))
+		}
+	}
+	return res
+}
+
+func deprecationstr(c Catalog, res string, d deprecatable) string {
+	notice, replacement := d.Deprecation()
+	if notice != "" {
+		res = msg(c, MsgDeprecationNotice, res, notice)
+	}
+	if replacement != "" {
+		res = msg(c, MsgDeprecationReplacement, res, replacement)
+	}
+	return res
+}
+
+// deprecationnote returns the suffix marking deprecated elements in the usage.
+func deprecationnote(c Catalog, d deprecatable) string {
+	if !d.Deprecated() {
+		return ""
+	}
+	res := msg(c, MsgDeprecatedNote)
+	if _, replacement := d.Deprecation(); replacement != "" {
+		res += msg(c, MsgReplacementNote, replacement)
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Env defines the environment an application is run in: the streams, environment variables, working
+// directory and clock. Actions, hooks and middleware access it with EnvFrom rather than using the process
+// globals, so that commands can be run in-process in tests with an environment of their own, see App.RunEnv.
+type Env struct {
+	Streams
+	// LookupEnv looks up an environment variable, os.LookupEnv by default.
+	LookupEnv func(key string) (string, bool)
+	// Dir is the working directory, the one of the process by default. Relative paths of response files
+	// given on the command line are resolved against it, see App.WithResponseFiles.
+	Dir string
+	// Now returns the current time for use in actions, time.Now by default.
+	Now func() time.Time
+}
+
+// Getenv returns the value of an environment variable, empty if not set. The variable is looked up in
+// the environment of the process if LookupEnv is not set.
+func (e Env) Getenv(key string) string {
+	lookup := e.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	value, _ := lookup(key)
+	return value
+}
+
+// ProcessEnv returns the environment of the process: the standard streams, environment variables,
+// working directory and clock.
+func ProcessEnv() Env {
+	dir, _ := os.Getwd()
+	return Env{Streams: StdStreams(), LookupEnv: os.LookupEnv, Dir: dir, Now: time.Now}
+}
+
+type envKey struct{}
+
+// EnvFrom returns the environment the application is run with for use in actions, hooks and middleware,
+// the environment of the process if the context does not originate from Run.
+func EnvFrom(ctx context.Context) Env {
+	if env, ok := ctx.Value(envKey{}).(Env); ok {
+		return env
+	}
+	return ProcessEnv()
+}
+
+// withEnv returns the context carrying the environment with unset fields defaulting to those of the process.
+func withEnv(ctx context.Context, env Env) (context.Context, Env) {
+	env = env.withDefaults()
+	return context.WithValue(ctx, envKey{}, env), env
+}
+
+// withDefaults returns the environment with the fields not set defaulting to those of the process.
+func (e Env) withDefaults() Env {
+	if e.In == nil {
+		e.In = os.Stdin
+	}
+	if e.Out == nil {
+		e.Out = os.Stdout
+	}
+	if e.Err == nil {
+		e.Err = os.Stderr
+	}
+	if e.LookupEnv == nil {
+		e.LookupEnv = os.LookupEnv
+	}
+	if e.Dir == "" {
+		e.Dir, _ = os.Getwd()
+	}
+	if e.Now == nil {
+		e.Now = time.Now
+	}
+	return e
+}
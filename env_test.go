@@ -0,0 +1,140 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/teris-io/cli"
+)
+
+func lookupMap(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func setupEnvApp() cli.App {
+	where := cli.NewCommand("where", "Show the environment").
+		WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+			env := cli.EnvFrom(ctx)
+			fmt.Fprintf(env.Out, "%s@%s %s\n", env.Getenv("USER"), env.Dir, env.Now().Format("2006-01-02"))
+			return nil
+		})
+	return cli.New("tool with a long description wrapped to the width given by the environment").
+		WithCommand(where).
+		WithBefore(func(ctx context.Context, args []string, options map[string]string) error {
+			if _, ok := cli.EnvFrom(ctx).LookupEnv("USER"); !ok {
+				return fmt.Errorf("USER not set")
+			}
+			return nil
+		})
+}
+
+func TestApp_RunEnv_ok(t *testing.T) {
+	out, errout := &stringwriter{}, &stringwriter{}
+	env := cli.Env{
+		Streams:   cli.Streams{In: strings.NewReader(""), Out: out, Err: errout},
+		LookupEnv: lookupMap(map[string]string{"USER": "joe"}),
+		Dir:       "/home/joe",
+		Now: func() time.Time {
+			return time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+		},
+	}
+	code := setupEnvApp().RunEnv(context.Background(), []string{"./tool", "where"}, env)
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "joe@/home/joe 2017-06-01\n", out.str)
+
+	env.LookupEnv = lookupMap(nil)
+	code = setupEnvApp().RunEnv(context.Background(), []string{"./tool", "where"}, env)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: USER not set\n", errout.str)
+}
+
+func TestApp_RunEnv_UsageWidthFromEnv_ok(t *testing.T) {
+	out := &stringwriter{}
+	env := cli.Env{Streams: cli.Streams{Out: out}, LookupEnv: lookupMap(map[string]string{"COLUMNS": "40"})}
+	code := setupEnvApp().WithUsageWidth(cli.AutoUsageWidth).RunEnv(context.Background(), []string{"./tool", "--help"}, env)
+	assertAppRunOk(t, 0, code)
+	expected := `tool
+
+Description:
+    tool with a long description wrapped
+    to the width given by the
+    environment
+
+Sub-commands:
+    tool where   Show the environment
+`
+	assertAppUsageOk(t, expected, out.str)
+}
+
+func TestEnvFrom_DefaultsToProcessEnv_ok(t *testing.T) {
+	env := cli.EnvFrom(context.Background())
+	dir, _ := os.Getwd()
+	if env.Dir != dir || env.Out != os.Stdout || env.LookupEnv == nil || env.Now == nil {
+		t.Errorf("expected the process environment, found %v", env)
+	}
+}
+
+func TestEnv_Getenv_DefaultsToProcess_ok(t *testing.T) {
+	os.Setenv("CLI_TEST_GETENV", "set")
+	defer os.Unsetenv("CLI_TEST_GETENV")
+	assertAppUsageOk(t, "set", cli.Env{}.Getenv("CLI_TEST_GETENV"))
+	assertAppUsageOk(t, "", cli.Env{LookupEnv: lookupMap(nil)}.Getenv("CLI_TEST_GETENV"))
+}
+
+func TestUsageEnv_LooksUpEnv_ok(t *testing.T) {
+	out := &stringwriter{}
+	env := cli.Env{Streams: cli.Streams{Out: out}, LookupEnv: lookupMap(map[string]string{"COLUMNS": "40"})}
+	if err := cli.UsageEnv(setupEnvApp().WithUsageWidth(cli.AutoUsageWidth), []string{"tool"}, env); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.str, "    tool with a long description wrapped\n") {
+		t.Errorf("expected the usage wrapped to COLUMNS of the environment, found %v", out.str)
+	}
+}
+
+func TestApp_RunEnv_ResponseFileRelativeToDir_ok(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "joe --home=/home/joe\n"})
+	defer os.RemoveAll(dir)
+
+	out := &stringwriter{}
+	a := cli.New("tool").
+		WithArg(cli.NewArg("user", "user")).
+		WithOption(cli.NewOption("home", "home directory")).
+		WithResponseFiles(true).
+		WithAction(func(args []string, options map[string]string) int {
+			fmt.Fprintf(out, "%v %v\n", args, options)
+			return 0
+		})
+	code := a.RunEnv(context.Background(), []string{"./tool", "@args.txt"}, cli.Env{Streams: cli.Streams{Out: out}, Dir: dir})
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "[joe] map[home:/home/joe]\n", out.str)
+}
+
+func TestUsageEnv_AutoWidthNotTerminal_ok(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	env := cli.Env{Streams: cli.Streams{Out: w}, LookupEnv: lookupMap(nil)}
+	err = cli.UsageEnv(setupEnvApp().WithUsageWidth(cli.AutoUsageWidth), []string{"tool"}, env)
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, _ := ioutil.ReadAll(r)
+	if !strings.Contains(string(out), "    tool with a long description wrapped to the width given by the environment\n") {
+		t.Errorf("expected the usage not to be wrapped for a pipe, found %s", out)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorAction defines a function type to be executed for an application or a command same as
+// ContextAction, but returning an error instead of an exit code. Run outputs the error prefixed with
+// `fatal:` and exits with code 1 unless the error is or wraps an ExitError, see ExitCode.
+type ErrorAction func(ctx context.Context, args []string, options map[string]string) error
+
+// ExitError defines an error carrying the exit code to be returned from Run. The error, including any
+// wrapping context, is output prefixed with `fatal:` unless the message is empty or the code is 0.
+type ExitError struct {
+	// Code is the exit code.
+	Code int
+	// Message is the error message, empty for exiting silently; ignored for the code 0.
+	Message string
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
+// ExitCode returns the exit code for an error returned from an ErrorAction: 0 for nil, the code of the
+// first ExitError in the chain of wrapped errors or 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+	return 1
+}
+
+// silent checks if Run exits silently on the error returned from an ErrorAction: for nil or if the first
+// ExitError in the chain of wrapped errors has no message or the exit code 0.
+func silent(err error) bool {
+	if err == nil {
+		return true
+	}
+	var exit *ExitError
+	return errors.As(err, &exit) && (exit.Message == "" || exit.Code == 0)
+}
+
+// errorAction returns the action to execute converted to an ErrorAction in the order of precedence:
+// ErrorAction, ContextAction and Action. Cancellable specifies if the action receives a context and
+// thus can handle signals, nil is returned if no action is defined.
+func errorAction(action Action, ctxact ContextAction, erract ErrorAction) (res ErrorAction, cancellable bool) {
+	switch {
+	case erract != nil:
+		return erract, true
+	case ctxact != nil:
+		return func(ctx context.Context, args []string, options map[string]string) error {
+			return exitError(ctxact(ctx, args, options))
+		}, true
+	case action != nil:
+		return func(ctx context.Context, args []string, options map[string]string) error {
+			return exitError(action(args, options))
+		}, false
+	}
+	return nil, false
+}
+
+// exitError converts an exit code into a silent ExitError, nil for success.
+func exitError(code int) error {
+	if code == 0 {
+		return nil
+	}
+	return &ExitError{Code: code}
+}
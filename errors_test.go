@@ -0,0 +1,73 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupErrorApp(err error) cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+			return err
+		})
+	return cli.New("git tool").WithCommand(co)
+}
+
+func TestApp_Run_ErrorAction_ok(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   int
+		output string
+	}{
+		{nil, 0, ""},
+		{errors.New("branch dev not found"), 1, "fatal: branch dev not found\n"},
+		{&cli.ExitError{Code: 3, Message: "merge conflict"}, 3, "fatal: merge conflict\n"},
+		{&cli.ExitError{Code: 4}, 4, ""},
+		{fmt.Errorf("checkout: %w", &cli.ExitError{Code: 5, Message: "dirty tree"}), 5, "fatal: checkout: dirty tree\n"},
+		{fmt.Errorf("checkout: %w", &cli.ExitError{Code: 2}), 2, ""},
+		{&cli.ExitError{Code: 0, Message: "nothing to do"}, 0, ""},
+	}
+	for _, test := range tests {
+		w := &stringwriter{}
+		code := setupErrorApp(test.err).Run([]string{"./git", "checkout", "dev"}, w)
+		assertAppRunOk(t, test.code, code)
+		assertAppUsageOk(t, test.output, w.str)
+	}
+}
+
+func TestApp_Run_ErrorActionTakesPrecedence_ok(t *testing.T) {
+	a := cli.New("git tool").
+		WithAction(func(args []string, options map[string]string) int {
+			return 1
+		}).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			return 2
+		}).
+		WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+			return &cli.ExitError{Code: 3}
+		})
+	code := a.Run([]string{"./git"}, &stringwriter{})
+	assertAppRunOk(t, 3, code)
+}
+
+func TestExitCode_ok(t *testing.T) {
+	tests := map[error]int{
+		nil:                     0,
+		errors.New("failed"):    1,
+		&cli.ExitError{Code: 2}: 2,
+		fmt.Errorf("wrapped: %w", &cli.ExitError{Code: 7, Message: "failed"}): 7,
+	}
+	for err, expected := range tests {
+		if code := cli.ExitCode(err); code != expected {
+			t.Errorf("expected exit code %d for %v, found %d", expected, err, code)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Example defines a usage example of the application or a command.
+type Example struct {
+	// Cmdline is the complete command line starting with the application name, e.g. `git checkout -b dev`.
+	// Arguments can be quoted as in a shell.
+	Cmdline string
+	// Explanation describes what the example does, optional.
+	Explanation string
+}
+
+// ValidateExamples parses the command lines of all examples of the application and its commands, including
+// hidden ones, and returns an error for the first example failing to parse or invoking a command other than
+// the one the example is attached to. Call it from a test to keep examples in line with the definition:
+//
+//	func TestExamples(t *testing.T) {
+//		if err := cli.ValidateExamples(app); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func ValidateExamples(a App) error {
+	if err := validateExamples(a, nil, a.Examples()); err != nil {
+		return err
+	}
+	var walk func(path []string, cmds []Command) error
+	walk = func(path []string, cmds []Command) error {
+		for _, cmd := range cmds {
+			cmdpath := append(append([]string{}, path...), cmd.Key())
+			if err := validateExamples(a, cmdpath, cmd.Examples()); err != nil {
+				return err
+			}
+			if err := walk(cmdpath, cmd.Commands()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(nil, a.Commands())
+}
+
+func validateExamples(a App, path []string, examples []Example) error {
+	for _, example := range examples {
+		appargs, err := splitShellQuoted(example.Cmdline)
+		if err == nil && len(appargs) == 0 {
+			err = fmt.Errorf("empty command line")
+		}
+		if err != nil {
+			return fmt.Errorf("example %q: %v", example.Cmdline, err)
+		}
+		res, err := ParseArgs(a, appargs)
+		if err != nil {
+			return fmt.Errorf("example %q: %v", example.Cmdline, err)
+		}
+		if invoked := strings.Join(res.Invocation[1:], " "); invoked != strings.Join(path, " ") {
+			return fmt.Errorf("example %q: invokes %q instead of %q", example.Cmdline, invoked, strings.Join(path, " "))
+		}
+	}
+	return nil
+}
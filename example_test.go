@@ -0,0 +1,98 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupExampleApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithShortcut("co").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithOption(cli.NewOption("create", "Create the branch if missing").WithChar('b').WithType(cli.TypeBool)).
+		WithExample("git checkout -b dev", "Check out the dev branch creating it if missing").
+		WithExample(`git co "feature/with space"`, "")
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithExample("git --help", "Show the usage")
+}
+
+func TestApp_Usage_Examples_ok(t *testing.T) {
+	w := &stringwriter{}
+	setupExampleApp().Run([]string{"./git", "co", "--help"}, w)
+	expected := `git checkout [--verbose] [--create] <branch>
+
+Description:
+    Check out a branch
+
+Arguments:
+    branch          branch to checkout
+
+Options:
+    -b, --create    Create the branch if missing
+
+Global options:
+    -v, --verbose   Verbose execution
+
+Examples:
+    git checkout -b dev
+        Check out the dev branch creating it if missing
+    git co "feature/with space"
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestMarkdown_Examples_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.Markdown(setupExampleApp(), []string{"git", "checkout"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := "## Examples\n\nCheck out the dev branch creating it if missing\n\n```\ngit checkout -b dev\n```\n\n```\ngit co \"feature/with space\"\n```\n"
+	if !strings.Contains(w.str, expected) {
+		t.Errorf("expected %q in %v", expected, w.str)
+	}
+}
+
+func TestManPage_Examples_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.ManPage(setupExampleApp(), []string{"git"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := ".SH EXAMPLES\n.TP\n\\fBgit \\-\\-help\\fR\nShow the usage\n"
+	if !strings.Contains(w.str, expected) {
+		t.Errorf("expected %q in %v", expected, w.str)
+	}
+}
+
+func TestValidateExamples_ok(t *testing.T) {
+	if err := cli.ValidateExamples(setupExampleApp()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateExamples_error(t *testing.T) {
+	tests := map[string]cli.App{
+		`example "git checkout --force dev": unknown option --force`: setupExampleApp().
+			WithExample("git checkout --force dev", ""),
+		`example "git --help": invokes "" instead of "checkout"`: cli.New("git tool").
+			WithCommand(cli.NewCommand("checkout", "Check out a branch").WithExample("git --help", "")),
+		`example "git 'dev": unterminated quote '`: cli.New("git tool").
+			WithArg(cli.NewArg("branch", "branch to checkout")).
+			WithExample("git 'dev", ""),
+		`example "": empty command line`: cli.New("git tool").
+			WithExample("", ""),
+	}
+	for expected, a := range tests {
+		err := cli.ValidateExamples(a)
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %q, found %v", expected, err)
+		}
+	}
+}
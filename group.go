@@ -0,0 +1,97 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+// optsection defines a headed section of options in the usage and documentation.
+type optsection struct {
+	name string
+	opts []Option
+}
+
+// cmdsection defines a headed section of commands in the usage and documentation.
+type cmdsection struct {
+	name string
+	cmds []Command
+}
+
+// optsections splits the options of a command and those inherited from its ancestors into sections:
+// ungrouped options of the command, one section per option group in the order of first appearance and
+// ungrouped inherited options. Grouped options are listed under their group regardless of being
+// inherited or not. Empty sections are omitted.
+func optsections(opts []Option, global []Option, name, globalname string) []optsection {
+	res := []optsection{{name: name}}
+	index := make(map[string]int)
+	for _, opt := range append(append([]Option{}, opts...), global...) {
+		if opt.Group() == "" {
+			continue
+		}
+		i, ok := index[opt.Group()]
+		if !ok {
+			i = len(res)
+			index[opt.Group()] = i
+			res = append(res, optsection{name: opt.Group()})
+		}
+		res[i].opts = append(res[i].opts, opt)
+	}
+	for _, opt := range opts {
+		if opt.Group() == "" {
+			res[0].opts = append(res[0].opts, opt)
+		}
+	}
+	res = append(res, optsection{name: globalname})
+	for _, opt := range global {
+		if opt.Group() == "" {
+			res[len(res)-1].opts = append(res[len(res)-1].opts, opt)
+		}
+	}
+	return nonEmptyOptsections(res)
+}
+
+func nonEmptyOptsections(sections []optsection) []optsection {
+	var res []optsection
+	for _, section := range sections {
+		if len(section.opts) > 0 {
+			res = append(res, section)
+		}
+	}
+	return res
+}
+
+// cmdsections splits commands into the section of uncategorised commands followed by one section
+// per category in the order of first appearance. Empty sections are omitted.
+func cmdsections(cmds []Command, name string) []cmdsection {
+	res := []cmdsection{{name: name}}
+	index := make(map[string]int)
+	for _, cmd := range cmds {
+		i, ok := index[cmd.Category()]
+		if cmd.Category() == "" {
+			i, ok = 0, true
+		}
+		if !ok {
+			i = len(res)
+			index[cmd.Category()] = i
+			res = append(res, cmdsection{name: cmd.Category()})
+		}
+		res[i].cmds = append(res[i].cmds, cmd)
+	}
+	if len(res[0].cmds) == 0 {
+		res = res[1:]
+	}
+	return res
+}
+
+// groupedOpts orders options keeping those of the same group together: ungrouped options first
+// followed by each group in the order of first appearance.
+func groupedOpts(opts []Option) []Option {
+	var res []Option
+	for _, section := range optsections(opts, nil, "", "") {
+		res = append(res, section.opts...)
+	}
+	return res
+}
+
+// synopsisOpts orders the inherited and own options of a command for its synopsis, see groupedOpts.
+func synopsisOpts(global, opts []Option) []Option {
+	return groupedOpts(append(append([]Option{}, global...), opts...))
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import "context"
+
+// Hook defines a function executed before or after the action of a command, e.g. to set up logging
+// from `--verbose`. It receives the same arguments and options as the action. Returning an error aborts
+// the execution, the error is handled same as one returned from an ErrorAction, e.g. an ExitError can be
+// returned to abort with a custom exit code.
+type Hook func(ctx context.Context, args []string, options map[string]string) error
+
+// Middleware defines a function wrapping the execution of a command, e.g. for timing or authentication.
+// The next function executes the before hooks, the action and the after hooks of the command along with
+// any middleware further down the invocation path. Not calling next aborts the execution.
+type Middleware func(next ErrorAction) ErrorAction
+
+// hookable defines the elements of the invocation path hooks and middleware can be attached to.
+type hookable interface {
+	Before() []Hook
+	After() []Hook
+	Middleware() []Middleware
+}
+
+// withHooks wraps the action into the hooks and middleware of the invocation path, the application
+// first. Before hooks are executed from the application down to the invoked command, after hooks in
+// reverse order once the action has succeeded. Middleware of the application is the outermost one.
+// Built-in commands are executed without hooks and middleware.
+func withHooks(action ErrorAction, path []hookable) ErrorAction {
+	res := func(ctx context.Context, args []string, options map[string]string) error {
+		for _, h := range path {
+			for _, hook := range h.Before() {
+				if err := hook(ctx, args, options); err != nil {
+					return err
+				}
+			}
+		}
+		if err := action(ctx, args, options); err != nil {
+			return err
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			for _, hook := range path[i].After() {
+				if err := hook(ctx, args, options); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		mws := path[i].Middleware()
+		for j := len(mws) - 1; j >= 0; j-- {
+			res = mws[j](res)
+		}
+	}
+	return res
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupHooksApp(trace *[]string, err error) cli.App {
+	hook := func(name string) cli.Hook {
+		return func(ctx context.Context, args []string, options map[string]string) error {
+			*trace = append(*trace, name)
+			return nil
+		}
+	}
+	mw := func(name string) cli.Middleware {
+		return func(next cli.ErrorAction) cli.ErrorAction {
+			return func(ctx context.Context, args []string, options map[string]string) error {
+				*trace = append(*trace, name+">")
+				err := next(ctx, args, options)
+				*trace = append(*trace, "<"+name)
+				return err
+			}
+		}
+	}
+
+	add := cli.NewCommand("add", "Add a remote").
+		WithArg(cli.NewArg("remote", "remote to add")).
+		WithBefore(hook("before-add")).
+		WithAfter(hook("after-add")).
+		WithMiddleware(mw("mw-add")).
+		WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+			*trace = append(*trace, "add "+args[0])
+			return err
+		})
+
+	rmt := cli.NewCommand("remote", "Work with git remotes").
+		WithCommand(add).
+		WithBefore(hook("before-remote")).
+		WithAfter(hook("after-remote"))
+
+	return cli.New("git tool").
+		WithCommand(rmt).
+		WithCommand(cli.NewCommand("status", "Show status")).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithBefore(hook("before-git")).
+		WithAfter(hook("after-git")).
+		WithMiddleware(mw("mw-git1")).
+		WithMiddleware(mw("mw-git2"))
+}
+
+func TestApp_Run_HooksAlongInvocationPath_ok(t *testing.T) {
+	var trace []string
+	code := setupHooksApp(&trace, nil).Run([]string{"./git", "remote", "add", "origin"}, &stringwriter{})
+	assertAppRunOk(t, 0, code)
+	expected := "mw-git1> mw-git2> mw-add> before-git before-remote before-add add origin after-add after-remote after-git <mw-add <mw-git2 <mw-git1"
+	assertAppUsageOk(t, expected, strings.Join(trace, " "))
+}
+
+func TestApp_Run_AfterHooksSkippedOnError_ok(t *testing.T) {
+	var trace []string
+	w := &stringwriter{}
+	code := setupHooksApp(&trace, errors.New("remote exists")).Run([]string{"./git", "remote", "add", "origin"}, w)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: remote exists\n", w.str)
+	expected := "mw-git1> mw-git2> mw-add> before-git before-remote before-add add origin <mw-add <mw-git2 <mw-git1"
+	assertAppUsageOk(t, expected, strings.Join(trace, " "))
+}
+
+func TestApp_Run_BeforeHookAborts_ok(t *testing.T) {
+	var trace []string
+	a := setupHooksApp(&trace, nil).
+		WithBefore(func(ctx context.Context, args []string, options map[string]string) error {
+			if _, ok := options["verbose"]; !ok {
+				return &cli.ExitError{Code: 77, Message: "not authenticated"}
+			}
+			return nil
+		})
+	w := &stringwriter{}
+	code := a.Run([]string{"./git", "remote", "add", "origin"}, w)
+	assertAppRunOk(t, 77, code)
+	assertAppUsageOk(t, "fatal: not authenticated\n", w.str)
+	expected := "mw-git1> mw-git2> mw-add> before-git <mw-add <mw-git2 <mw-git1"
+	assertAppUsageOk(t, expected, strings.Join(trace, " "))
+
+	trace = nil
+	code = a.Run([]string{"./git", "remote", "add", "-v", "origin"}, &stringwriter{})
+	assertAppRunOk(t, 0, code)
+}
+
+func TestApp_Run_HooksNotRunWithoutAction_ok(t *testing.T) {
+	var trace []string
+	code := setupHooksApp(&trace, nil).Run([]string{"./git", "status"}, &stringwriter{})
+	assertAppRunOk(t, 1, code)
+	if len(trace) != 0 {
+		t.Errorf("expected no hooks to run, found %v", trace)
+	}
+}
+
+func TestApp_Run_HooksNotRunForBuiltins_ok(t *testing.T) {
+	var trace []string
+	a := setupHooksApp(&trace, nil).
+		WithVersion("1.0.0").
+		WithHelpCommand().
+		WithVersionCommand().
+		WithCompletionCommand()
+	for _, appargs := range [][]string{
+		{"./git", "help", "remote"},
+		{"./git", "version"},
+		{"./git", "completion", "bash"},
+		{"./git", "__complete", "--", "rem"},
+		{"./git", "remote", "add", "--help"},
+		{"./git", "--version"},
+	} {
+		code := a.Run(appargs, &stringwriter{})
+		assertAppRunOk(t, 0, code)
+	}
+	if len(trace) != 0 {
+		t.Errorf("expected no hooks or middleware to run for built-in commands, found %v", trace)
+	}
+}
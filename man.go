@@ -0,0 +1,186 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManPage outputs the roff man page in section 1 for the command given by the invocation path, e.g.
+// `[gitc remote add]`. The output is deterministic, e.g. no date is included. Hidden commands and
+// options are excluded. The page is output in the language set with App.WithLanguage, English otherwise,
+// and does not depend on the environment.
+func ManPage(a App, invocation []string, w io.Writer) error {
+	sc, err := resolve(a, invocation)
+	if err != nil {
+		return err
+	}
+	opts := visibleOpts(sc.opts)
+	global := visibleOpts(sc.global)
+	cmds := visibleCmds(sc.cmds)
+	c := doccatalog(a)
+
+	name := strings.Join(invocation, "-")
+	fmt.Fprintf(w, ".TH \"%s\" \"1\" \"\" \"\" \"%s\"\n", roffescape(strings.ToUpper(name)), roffescape(invocation[0]))
+	fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgName))
+	fmt.Fprintf(w, "%s \\- %s\n", roffescape(name), roffescape(sc.descr))
+
+	fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgSynopsis))
+	fmt.Fprintf(w, ".B %s\n", roffescape(strings.Join(invocation, " ")))
+	if synopsis := strings.TrimSpace(optstring(synopsisOpts(global, opts)) + argstring(sc.args, sc.passthrough)); synopsis != "" {
+		fmt.Fprintln(w, roffline(synopsis))
+	}
+
+	fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgDescription))
+	fmt.Fprintln(w, roffline(sc.descr))
+
+	args := sc.args
+	if sc.passthrough != nil {
+		args = append(append([]Arg{}, args...), sc.passthrough)
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgArguments))
+		for i, arg := range args {
+			descr := arg.Description()
+			key := arg.Key()
+			if sc.passthrough != nil && i == len(args)-1 {
+				key = "-- " + key
+			} else if arg.Optional() {
+				descr += msg(c, MsgOptional)
+			}
+			fmt.Fprintln(w, ".TP")
+			fmt.Fprintf(w, "\\fB%s\\fR\n", roffescape(key))
+			fmt.Fprintln(w, roffline(descr+deprecationnote(c, arg)))
+		}
+	}
+
+	for _, section := range optsections(opts, global, msg(c, MsgOptions), msg(c, MsgGlobalOptions)) {
+		fmt.Fprintf(w, ".SH %s\n", roffescape(strings.ToUpper(section.name)))
+		for _, opt := range section.opts {
+			fmt.Fprintln(w, ".TP")
+			key := "\\fB\\-\\-" + roffescape(opt.Key()) + "\\fR"
+			if opt.Type() != TypeBool {
+				key += "=\\fI" + roffescape(typestr(opt.Type(), "")) + "\\fR"
+			}
+			if opt.CharKey() != rune(0) {
+				key = "\\fB\\-" + roffescape(string(opt.CharKey())) + "\\fR, " + key
+			}
+			fmt.Fprintln(w, key)
+			fmt.Fprintln(w, roffline(opt.Description()+deprecationnote(c, opt)))
+		}
+	}
+
+	for _, section := range cmdsections(cmds, msg(c, MsgCommands)) {
+		fmt.Fprintf(w, ".SH %s\n", roffescape(strings.ToUpper(section.name)))
+		for _, cmd := range section.cmds {
+			descr := cmd.Description()
+			if cmd.Shortcut() != "" {
+				descr += msg(c, MsgShortcut, cmd.Shortcut())
+			}
+			fmt.Fprintln(w, ".TP")
+			fmt.Fprintf(w, "\\fB%s\\fR\n", roffescape(strings.Join(invocation, " ")+" "+cmd.Key()))
+			fmt.Fprintln(w, roffline(descr+deprecationnote(c, cmd)))
+		}
+	}
+
+	if len(sc.examples) > 0 {
+		fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgExamples))
+	}
+	for _, example := range sc.examples {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, "\\fB%s\\fR\n", roffescape(example.Cmdline))
+		if example.Explanation != "" {
+			fmt.Fprintln(w, roffline(example.Explanation))
+		}
+	}
+
+	var seealso []string
+	if len(invocation) > 1 {
+		seealso = append(seealso, strings.Join(invocation[:len(invocation)-1], "-"))
+	}
+	for _, cmd := range cmds {
+		seealso = append(seealso, name+"-"+cmd.Key())
+	}
+	if len(seealso) > 0 {
+		fmt.Fprintf(w, ".SH %s\n", manheading(c, MsgSeeAlso))
+		for i, page := range seealso {
+			sep := ","
+			if i == len(seealso)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(w, ".BR %s (1)%s\n", roffescape(page), sep)
+		}
+	}
+	return nil
+}
+
+// ManPages writes man pages for the application named appname and all its (non-hidden) commands into
+// the directory dir, one per command path, e.g. `gitc-remote-add.1`.
+func ManPages(a App, appname, dir string) error {
+	for _, invocation := range invocations(a, appname) {
+		fname := filepath.Join(dir, strings.Join(invocation, "-")+".1")
+		f, err := os.Create(fname)
+		if err != nil {
+			return err
+		}
+		err = ManPage(a, invocation, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invocations returns the invocation paths of the application and all its non-hidden commands, depth first.
+func invocations(a App, appname string) [][]string {
+	var res [][]string
+	var walk func(invocation []string, cmds []Command)
+	walk = func(invocation []string, cmds []Command) {
+		res = append(res, invocation)
+		for _, cmd := range visibleCmds(cmds) {
+			walk(append(append([]string{}, invocation...), cmd.Key()), cmd.Commands())
+		}
+	}
+	walk([]string{appname}, a.Commands())
+	return res
+}
+
+// typestr returns the name of a value type prefixed with sep, empty for boolean values.
+func typestr(tp ValueType, sep string) string {
+	switch tp {
+	case TypeString:
+		return sep + "string"
+	case TypeInt:
+		return sep + "int"
+	case TypeNumber:
+		return sep + "number"
+	}
+	return ""
+}
+
+func roffescape(s string) string {
+	s = strings.Replace(s, `\`, `\e`, -1)
+	return strings.Replace(s, "-", `\-`, -1)
+}
+
+// roffline escapes a line of text guarding against leading control characters.
+func roffline(s string) string {
+	s = roffescape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// manheading returns the section heading with the given identifier from the catalog in upper case.
+func manheading(c Catalog, id MessageID) string {
+	return roffescape(strings.ToUpper(msg(c, id)))
+}
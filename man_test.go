@@ -0,0 +1,162 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupManApp() cli.App {
+	add := cli.NewCommand("add", "Add a remote").
+		WithArg(cli.NewArg("remote", "remote to add")).
+		WithArg(cli.NewArg("url", "remote url").AsOptional()).
+		WithOption(cli.NewOption("fetch", "Fetch after adding").WithChar('f').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("depth", "Fetch depth").WithType(cli.TypeInt))
+
+	rmt := cli.NewCommand("remote", "Work with git remotes").
+		WithCommand(add).
+		WithCommand(cli.NewCommand("prune", "Prune stale branches").WithShortcut("p")).
+		WithCommand(cli.NewCommand("debug", "Debug").AsHidden())
+
+	return cli.New("git tool").
+		WithCommand(rmt).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool))
+}
+
+func TestManPage_NestedCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.ManPage(setupManApp(), []string{"gitc", "remote", "add"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := `.TH "GITC\-REMOTE\-ADD" "1" "" "" "gitc"
+.SH NAME
+gitc\-remote\-add \- Add a remote
+.SH SYNOPSIS
+.B gitc remote add
+[\-\-verbose] [\-\-fetch] [\-\-depth=int] <remote> [url]
+.SH DESCRIPTION
+Add a remote
+.SH ARGUMENTS
+.TP
+\fBremote\fR
+remote to add
+.TP
+\fBurl\fR
+remote url, optional
+.SH OPTIONS
+.TP
+\fB\-f\fR, \fB\-\-fetch\fR
+Fetch after adding
+.TP
+\fB\-\-depth\fR=\fIint\fR
+Fetch depth
+.SH GLOBAL OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+Verbose execution
+.SH SEE ALSO
+.BR gitc\-remote (1)
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestManPage_CommandWithSubCommands_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.ManPage(setupManApp(), []string{"gitc", "remote"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := `.TH "GITC\-REMOTE" "1" "" "" "gitc"
+.SH NAME
+gitc\-remote \- Work with git remotes
+.SH SYNOPSIS
+.B gitc remote
+[\-\-verbose]
+.SH DESCRIPTION
+Work with git remotes
+.SH GLOBAL OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+Verbose execution
+.SH COMMANDS
+.TP
+\fBgitc remote add\fR
+Add a remote
+.TP
+\fBgitc remote prune\fR
+Prune stale branches, shortcut: p
+.SH SEE ALSO
+.BR gitc (1),
+.BR gitc\-remote\-add (1),
+.BR gitc\-remote\-prune (1)
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestManPages_WritesPagePerCommandPath_ok(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err = cli.ManPages(setupManApp(), "gitc", dir); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.1"))
+	for i, f := range files {
+		files[i] = filepath.Base(f)
+	}
+	sort.Strings(files)
+	assertAppUsageOk(t, "[gitc-remote-add.1 gitc-remote-prune.1 gitc-remote.1 gitc.1]", fmt.Sprintf("%v", files))
+}
+
+func TestManPage_OptionGroupsAndCommandCategories_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.ManPage(setupGroupApp(), []string{"git", "checkout"}, w); err != nil {
+		t.Fatal(err)
+	}
+	synopsis := `[\-\-verbose] [\-\-force] [\-\-proxy=string] [\-\-depth=int] [\-\-color] <branch>`
+	if !strings.Contains(w.str, ".SH SYNOPSIS\n.B git checkout\n"+synopsis+"\n") {
+		t.Errorf("expected synopsis %q in %v", synopsis, w.str)
+	}
+	expected := `.SH OPTIONS
+.TP
+\fB\-f\fR, \fB\-\-force\fR
+Discard local changes
+.SH NETWORKING
+.TP
+\fB\-\-depth\fR=\fIint\fR
+History depth
+.TP
+\fB\-\-proxy\fR=\fIstring\fR
+Proxy URL
+.SH OUTPUT
+.TP
+\fB\-\-color\fR
+Color output
+.SH GLOBAL OPTIONS
+.TP
+\fB\-v\fR, \fB\-\-verbose\fR
+Verbose execution
+`
+	if !strings.Contains(w.str, expected) {
+		t.Errorf("expected %v in %v", expected, w.str)
+	}
+
+	w = &stringwriter{}
+	if err := cli.ManPage(setupGroupApp(), []string{"git"}, w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.str, ".SH COMMANDS\n.TP\n\\fBgit checkout\\fR\n") || !strings.Contains(w.str, ".SH PLUMBING\n.TP\n\\fBgit cat\\-file\\fR\n") {
+		t.Errorf("expected command sections in %v", w.str)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markdown outputs the Markdown reference page for the command given by the invocation path, e.g.
+// `[gitc remote add]`, linking sub-commands to pages as written by MarkdownPages. Hidden commands and
+// options are excluded. The page is output in the language set with App.WithLanguage, English otherwise,
+// and does not depend on the environment.
+func Markdown(a App, invocation []string, w io.Writer) error {
+	return markdown(a, invocation, w, "#", func(invocation []string) string {
+		return strings.Join(invocation, "-") + ".md"
+	})
+}
+
+// MarkdownPages writes Markdown reference pages for the application named appname and all its
+// (non-hidden) commands into the directory dir, one per command path, e.g. `gitc-remote-add.md`.
+func MarkdownPages(a App, appname, dir string) error {
+	for _, invocation := range invocations(a, appname) {
+		fname := filepath.Join(dir, strings.Join(invocation, "-")+".md")
+		f, err := os.Create(fname)
+		if err != nil {
+			return err
+		}
+		err = Markdown(a, invocation, f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkdownAll outputs the Markdown reference for the application named appname and all its (non-hidden)
+// commands as a single document linking sub-commands to their sections.
+func MarkdownAll(a App, appname string, w io.Writer) error {
+	for i, invocation := range invocations(a, appname) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		err := markdown(a, invocation, w, "##", func(invocation []string) string {
+			return "#" + strings.Join(invocation, "-")
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdown(a App, invocation []string, w io.Writer, heading string, link func([]string) string) error {
+	sc, err := resolve(a, invocation)
+	if err != nil {
+		return err
+	}
+	opts := visibleOpts(sc.opts)
+	global := visibleOpts(sc.global)
+	cmds := visibleCmds(sc.cmds)
+	c := doccatalog(a)
+	thiscmd := strings.Join(invocation, " ")
+
+	fmt.Fprintf(w, "%s %s\n\n", heading, thiscmd)
+	fmt.Fprintf(w, "%s\n\n", sc.descr)
+	fmt.Fprintf(w, "%s# %s\n\n", heading, msg(c, MsgSynopsis))
+	fmt.Fprintf(w, "```\n%s%s%s\n```\n", thiscmd, optstring(synopsisOpts(global, opts)), argstring(sc.args, sc.passthrough))
+
+	args := sc.args
+	if sc.passthrough != nil {
+		args = append(append([]Arg{}, args...), sc.passthrough)
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(w, "\n%s# %s\n\n", heading, msg(c, MsgArguments))
+		mdheader(w, msg(c, MsgArgumentColumn), msg(c, MsgTypeColumn), msg(c, MsgOptionalColumn), msg(c, MsgDescription))
+		for i, arg := range args {
+			key := arg.Key()
+			optional := msg(c, MsgNo)
+			if sc.passthrough != nil && i == len(args)-1 {
+				key = "-- " + key
+				optional = msg(c, MsgYes)
+			} else if arg.Optional() {
+				optional = msg(c, MsgYes)
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", key, typename(arg.Type()), optional,
+				mdcell(arg.Description()+deprecationnote(c, arg)))
+		}
+	}
+
+	for _, section := range optsections(opts, global, msg(c, MsgOptions), msg(c, MsgGlobalOptions)) {
+		fmt.Fprintf(w, "\n%s# %s\n\n", heading, section.name)
+		mdheader(w, msg(c, MsgOptionColumn), msg(c, MsgCharColumn), msg(c, MsgTypeColumn), msg(c, MsgDescription))
+		for _, opt := range section.opts {
+			char := ""
+			if opt.CharKey() != rune(0) {
+				char = "`-" + string(opt.CharKey()) + "`"
+			}
+			fmt.Fprintf(w, "| `--%s` | %s | %s | %s |\n", opt.Key(), char, typename(opt.Type()),
+				mdcell(opt.Description()+deprecationnote(c, opt)))
+		}
+	}
+
+	for _, section := range cmdsections(cmds, msg(c, MsgSubCommands)) {
+		fmt.Fprintf(w, "\n%s# %s\n\n", heading, section.name)
+		mdheader(w, msg(c, MsgCommandColumn), msg(c, MsgShortcutColumn), msg(c, MsgDescription))
+		for _, cmd := range section.cmds {
+			sub := append(append([]string{}, invocation...), cmd.Key())
+			fmt.Fprintf(w, "| [`%s`](%s) | %s | %s |\n", strings.Join(sub, " "), link(sub), mdcell(cmd.Shortcut()),
+				mdcell(cmd.Description()+deprecationnote(c, cmd)))
+		}
+	}
+
+	if len(sc.examples) > 0 {
+		fmt.Fprintf(w, "\n%s# %s\n", heading, msg(c, MsgExamples))
+	}
+	for _, example := range sc.examples {
+		if example.Explanation != "" {
+			fmt.Fprintf(w, "\n%s\n", example.Explanation)
+		}
+		fmt.Fprintf(w, "\n```\n%s\n```\n", example.Cmdline)
+	}
+
+	if len(invocation) > 1 {
+		parent := invocation[:len(invocation)-1]
+		fmt.Fprintf(w, "\n%s# %s\n\n", heading, msg(c, MsgSeeAlso))
+		fmt.Fprintf(w, "* [`%s`](%s)\n", strings.Join(parent, " "), link(parent))
+	}
+	return nil
+}
+
+// typename returns the name of a value type.
+func typename(tp ValueType) string {
+	if tp == TypeBool {
+		return "bool"
+	}
+	return typestr(tp, "")
+}
+
+// mdheader writes the header of a table with the given columns.
+func mdheader(w io.Writer, columns ...string) {
+	fmt.Fprintf(w, "| %s |\n", strings.Join(columns, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat("---|", len(columns)))
+}
+
+func mdcell(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func TestMarkdown_NestedCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.Markdown(setupManApp(), []string{"gitc", "remote", "add"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := "# gitc remote add\n\nAdd a remote\n\n## Synopsis\n\n" +
+		"```\ngitc remote add [--verbose] [--fetch] [--depth=int] <remote> [url]\n```\n" + `
+## Arguments
+
+| Argument | Type | Optional | Description |
+|---|---|---|---|
+| ` + "`remote`" + ` | string | no | remote to add |
+| ` + "`url`" + ` | string | yes | remote url |
+
+## Options
+
+| Option | Char | Type | Description |
+|---|---|---|---|
+| ` + "`--fetch` | `-f`" + ` | bool | Fetch after adding |
+| ` + "`--depth`" + ` |  | int | Fetch depth |
+
+## Global options
+
+| Option | Char | Type | Description |
+|---|---|---|---|
+| ` + "`--verbose` | `-v`" + ` | bool | Verbose execution |
+
+## See also
+
+* [` + "`gitc remote`" + `](gitc-remote.md)
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestMarkdownAll_LinksSections_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.MarkdownAll(setupManApp(), "gitc", w); err != nil {
+		t.Fatal(err)
+	}
+	for _, fragment := range []string{
+		"## gitc\n",
+		"## gitc remote prune\n",
+		"| [`gitc remote add`](#gitc-remote-add) |  | Add a remote |\n",
+		"| [`gitc remote prune`](#gitc-remote-prune) | p | Prune stale branches |\n",
+		"* [`gitc remote`](#gitc-remote)\n",
+	} {
+		if !strings.Contains(w.str, fragment) {
+			t.Errorf("expected markdown to contain %v, found: %v", fragment, w.str)
+		}
+	}
+	if strings.Contains(w.str, "debug") {
+		t.Errorf("expected markdown to exclude hidden commands, found: %v", w.str)
+	}
+}
+
+func TestMarkdown_OptionGroupsAndCommandCategories_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.Markdown(setupGroupApp(), []string{"git"}, w); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"```\ngit [--verbose] [--proxy=string] [--color]\n```",
+		"## Options\n\n| Option | Char | Type | Description |\n|---|---|---|---|\n| `--verbose` |",
+		"## Networking\n\n| Option | Char | Type | Description |\n|---|---|---|---|\n| `--proxy` |",
+		"## Output\n\n| Option | Char | Type | Description |\n|---|---|---|---|\n| `--color` |",
+		"## Sub-commands\n\n| Command | Shortcut | Description |\n|---|---|---|\n| [`git checkout`]",
+		"## Plumbing\n\n| Command | Shortcut | Description |\n|---|---|---|\n| [`git cat-file`]",
+	} {
+		if !strings.Contains(w.str, expected) {
+			t.Errorf("expected %q in %v", expected, w.str)
+		}
+	}
+}
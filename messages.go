@@ -0,0 +1,248 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MessageID identifies a user-facing message in a Catalog.
+type MessageID string
+
+// MessageID constants for all user-facing messages. The English format of each message is given in
+// English, the arguments of translations must match in number, order and type.
+const (
+	MsgFatal   MessageID = "fatal"
+	MsgUsage   MessageID = "usage"
+	MsgWarning MessageID = "warning"
+
+	MsgUnknownOption     MessageID = "unknown-option"
+	MsgUnknownFlag       MessageID = "unknown-flag"
+	MsgMissingValue      MessageID = "missing-value"
+	MsgDanglingOption    MessageID = "dangling-option"
+	MsgNonTerminalFlag   MessageID = "non-terminal-flag"
+	MsgMissingArgument   MessageID = "missing-argument"
+	MsgUnknownArguments  MessageID = "unknown-arguments"
+	MsgBoolOptionValue   MessageID = "bool-option-value"
+	MsgIntOptionValue    MessageID = "int-option-value"
+	MsgNumberOptionValue MessageID = "number-option-value"
+	MsgBoolArgValue      MessageID = "bool-arg-value"
+	MsgIntArgValue       MessageID = "int-arg-value"
+	MsgNumberArgValue    MessageID = "number-arg-value"
+	MsgUnknownHelpTopic  MessageID = "unknown-help-topic"
+	MsgInvalidInvocation MessageID = "invalid-invocation"
+	MsgUnsupportedShell  MessageID = "unsupported-shell"
+	MsgVersionNotSet     MessageID = "version-not-set"
+
+	MsgResponseFile      MessageID = "response-file"
+	MsgResponseFileLine  MessageID = "response-file-line"
+	MsgCyclicReference   MessageID = "cyclic-reference"
+	MsgUnterminatedQuote MessageID = "unterminated-quote"
+	MsgTrailingBackslash MessageID = "trailing-backslash"
+
+	MsgDeprecatedCommand      MessageID = "deprecated-command"
+	MsgDeprecatedOption       MessageID = "deprecated-option"
+	MsgDeprecatedArgument     MessageID = "deprecated-argument"
+	MsgDeprecationNotice      MessageID = "deprecation-notice"
+	MsgDeprecationReplacement MessageID = "deprecation-replacement"
+
+	MsgDescription       MessageID = "description"
+	MsgArguments         MessageID = "arguments"
+	MsgOptions           MessageID = "options"
+	MsgGlobalOptions     MessageID = "global-options"
+	MsgSubCommands       MessageID = "sub-commands"
+	MsgHelpTopics        MessageID = "help-topics"
+	MsgExamples          MessageID = "examples"
+	MsgOptional          MessageID = "optional"
+	MsgFollowingDashDash MessageID = "following-dashdash"
+	MsgShortcut          MessageID = "shortcut"
+	MsgDeprecatedNote    MessageID = "deprecated-note"
+	MsgReplacementNote   MessageID = "replacement-note"
+
+	MsgName           MessageID = "name"
+	MsgSynopsis       MessageID = "synopsis"
+	MsgCommands       MessageID = "commands"
+	MsgSeeAlso        MessageID = "see-also"
+	MsgArgumentColumn MessageID = "argument-column"
+	MsgOptionColumn   MessageID = "option-column"
+	MsgCommandColumn  MessageID = "command-column"
+	MsgTypeColumn     MessageID = "type-column"
+	MsgCharColumn     MessageID = "char-column"
+	MsgOptionalColumn MessageID = "optional-column"
+	MsgShortcutColumn MessageID = "shortcut-column"
+	MsgYes            MessageID = "yes"
+	MsgNo             MessageID = "no"
+)
+
+// Catalog defines a catalog of messages in one language, see App.WithCatalog.
+type Catalog interface {
+	// Message returns the fmt format of the message with the given identifier, false if the message
+	// is not translated, in which case the English message is used.
+	Message(id MessageID) (format string, ok bool)
+}
+
+// MapCatalog defines a Catalog given by a map of message identifiers to their fmt formats.
+type MapCatalog map[MessageID]string
+
+// Message returns the format of the message with the given identifier.
+func (c MapCatalog) Message(id MessageID) (string, bool) {
+	format, ok := c[id]
+	return format, ok
+}
+
+// English is the default catalog.
+var English = MapCatalog{
+	MsgFatal:   "fatal: %v",
+	MsgUsage:   "usage: %v",
+	MsgWarning: "warning: %v",
+
+	MsgUnknownOption:     "unknown option --%s",
+	MsgUnknownFlag:       "unknown flag -%s",
+	MsgMissingValue:      "missing value for option --%s",
+	MsgDanglingOption:    "dangling option --%s",
+	MsgNonTerminalFlag:   "non-boolean flag -%s in non-terminal position",
+	MsgMissingArgument:   "missing required argument %s",
+	MsgUnknownArguments:  "unknown arguments %v",
+	MsgBoolOptionValue:   "boolean options have true assigned implicitly, found value for --%s",
+	MsgIntOptionValue:    "option --%s must be given an integer value, found %v",
+	MsgNumberOptionValue: "option --%s must must be given a number, found %v",
+	MsgBoolArgValue:      "argument %s must be a boolean value, found %v",
+	MsgIntArgValue:       "argument %s must be an integer value, found %v",
+	MsgNumberArgValue:    "argument %s must be a number, found %v",
+	MsgUnknownHelpTopic:  "unknown command or help topic %s",
+	MsgInvalidInvocation: "invalid invocation path %v",
+	MsgUnsupportedShell:  "unsupported shell %s",
+	MsgVersionNotSet:     "no version set",
+
+	MsgResponseFile:      "response file %s: %v",
+	MsgResponseFileLine:  "%s:%d: %v",
+	MsgCyclicReference:   "cyclic reference",
+	MsgUnterminatedQuote: "unterminated quote %c",
+	MsgTrailingBackslash: "trailing backslash",
+
+	MsgDeprecatedCommand:      "command %s is deprecated",
+	MsgDeprecatedOption:       "option --%s is deprecated",
+	MsgDeprecatedArgument:     "argument %s is deprecated",
+	MsgDeprecationNotice:      "%s: %s",
+	MsgDeprecationReplacement: "%s, use %s instead",
+
+	MsgDescription:       "Description",
+	MsgArguments:         "Arguments",
+	MsgOptions:           "Options",
+	MsgGlobalOptions:     "Global options",
+	MsgSubCommands:       "Sub-commands",
+	MsgHelpTopics:        "Help topics",
+	MsgExamples:          "Examples",
+	MsgOptional:          ", optional",
+	MsgFollowingDashDash: ", following --",
+	MsgShortcut:          ", shortcut: %s",
+	MsgDeprecatedNote:    ", deprecated",
+	MsgReplacementNote:   ", use %s",
+
+	MsgName:           "Name",
+	MsgSynopsis:       "Synopsis",
+	MsgCommands:       "Commands",
+	MsgSeeAlso:        "See also",
+	MsgArgumentColumn: "Argument",
+	MsgOptionColumn:   "Option",
+	MsgCommandColumn:  "Command",
+	MsgTypeColumn:     "Type",
+	MsgCharColumn:     "Char",
+	MsgOptionalColumn: "Optional",
+	MsgShortcutColumn: "Shortcut",
+	MsgYes:            "yes",
+	MsgNo:             "no",
+}
+
+// msg formats the message with the given identifier from the catalog falling back to English.
+func msg(c Catalog, id MessageID, args ...interface{}) string {
+	format, ok := "", false
+	if c != nil {
+		format, ok = c.Message(id)
+	}
+	if !ok {
+		format = English[id]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// catalog returns the catalog for the language of the application, which is either set explicitly or
+// taken from the LC_ALL, LC_MESSAGES or LANG environment variables looked up with lookup, e.g.
+// `de_DE.UTF-8`. Catalogs are matched by the language and territory, e.g. `de_DE`, then by the language
+// only, e.g. `de`. English is returned if no catalog matches.
+func catalog(a App, lookup func(string) (string, bool)) Catalog {
+	catalogs := a.Catalogs()
+	if len(catalogs) == 0 {
+		return English
+	}
+	lang := a.Language()
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang != "" {
+			break
+		}
+		lang, _ = lookup(key)
+	}
+	// strip the encoding and modifier, e.g. de_DE.UTF-8@euro
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.Replace(lang, "-", "_", -1)
+	if c, ok := catalogs[lang]; ok {
+		return c
+	}
+	if i := strings.Index(lang, "_"); i >= 0 {
+		if c, ok := catalogs[lang[:i]]; ok {
+			return c
+		}
+	}
+	return English
+}
+
+// localised defines errors with messages available in all catalogs.
+type localised interface {
+	error
+	message(c Catalog) string
+}
+
+// errmsg returns the message of the error from the catalog if available. For errors wrapping a localised
+// one, e.g. in hooks, the message of the latter is replaced keeping the context added by wrapping.
+func errmsg(c Catalog, err error) string {
+	var e localised
+	if errors.As(err, &e) {
+		return strings.Replace(err.Error(), e.Error(), e.message(c), 1)
+	}
+	return err.Error()
+}
+
+// doccatalog returns the catalog for the language set explicitly with WithLanguage, English otherwise,
+// so that man pages and Markdown do not depend on the environment they are generated in.
+func doccatalog(a App) Catalog {
+	return catalog(a, func(string) (string, bool) {
+		return "", false
+	})
+}
+
+// messageError defines errors given by a message from the catalog.
+type messageError struct {
+	id   MessageID
+	args []interface{}
+}
+
+// errorf returns an error with the message with the given identifier formatted with args.
+func errorf(id MessageID, args ...interface{}) error {
+	return &messageError{id: id, args: args}
+}
+
+func (e *messageError) Error() string {
+	return e.message(English)
+}
+
+func (e *messageError) message(c Catalog) string {
+	return msg(c, e.id, e.args...)
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+var german = cli.MapCatalog{
+	cli.MsgFatal:   "Fehler: %v",
+	cli.MsgUsage:   "Aufruf: %v",
+	cli.MsgWarning: "Warnung: %v",
+
+	cli.MsgUnknownOption:     "unbekannte Option --%s",
+	cli.MsgUnknownFlag:       "unbekanntes Flag -%s",
+	cli.MsgMissingValue:      "Wert für Option --%s fehlt",
+	cli.MsgDanglingOption:    "Option --%s ohne Wert",
+	cli.MsgNonTerminalFlag:   "nicht boolesches Flag -%s nicht an letzter Stelle",
+	cli.MsgMissingArgument:   "erforderliches Argument %s fehlt",
+	cli.MsgUnknownArguments:  "unbekannte Argumente %v",
+	cli.MsgBoolOptionValue:   "boolesche Optionen sind implizit wahr, Wert für --%s gefunden",
+	cli.MsgIntOptionValue:    "Option --%s erwartet eine ganze Zahl, %v gefunden",
+	cli.MsgNumberOptionValue: "Option --%s erwartet eine Zahl, %v gefunden",
+	cli.MsgBoolArgValue:      "Argument %s erwartet einen Wahrheitswert, %v gefunden",
+	cli.MsgIntArgValue:       "Argument %s erwartet eine ganze Zahl, %v gefunden",
+	cli.MsgNumberArgValue:    "Argument %s erwartet eine Zahl, %v gefunden",
+	cli.MsgUnknownHelpTopic:  "unbekannter Befehl oder Hilfethema %s",
+	cli.MsgInvalidInvocation: "ungültiger Aufrufpfad %v",
+	cli.MsgUnsupportedShell:  "nicht unterstützte Shell %s",
+	cli.MsgVersionNotSet:     "keine Version gesetzt",
+
+	cli.MsgResponseFile:      "Antwortdatei %s: %v",
+	cli.MsgResponseFileLine:  "%s:%d: %v",
+	cli.MsgCyclicReference:   "zyklischer Verweis",
+	cli.MsgUnterminatedQuote: "nicht geschlossenes Anführungszeichen %c",
+	cli.MsgTrailingBackslash: "abschließender Backslash",
+
+	cli.MsgDeprecatedCommand:      "Befehl %s ist veraltet",
+	cli.MsgDeprecatedOption:       "Option --%s ist veraltet",
+	cli.MsgDeprecatedArgument:     "Argument %s ist veraltet",
+	cli.MsgDeprecationNotice:      "%s: %s",
+	cli.MsgDeprecationReplacement: "%s, stattdessen %s verwenden",
+
+	cli.MsgDescription:       "Beschreibung",
+	cli.MsgArguments:         "Argumente",
+	cli.MsgOptions:           "Optionen",
+	cli.MsgGlobalOptions:     "Globale Optionen",
+	cli.MsgSubCommands:       "Unterbefehle",
+	cli.MsgHelpTopics:        "Hilfethemen",
+	cli.MsgExamples:          "Beispiele",
+	cli.MsgOptional:          ", optional",
+	cli.MsgFollowingDashDash: ", nach --",
+	cli.MsgShortcut:          ", Kurzform: %s",
+	cli.MsgDeprecatedNote:    ", veraltet",
+	cli.MsgReplacementNote:   ", stattdessen %s",
+
+	cli.MsgName:           "Bezeichnung",
+	cli.MsgSynopsis:       "Übersicht",
+	cli.MsgCommands:       "Befehle",
+	cli.MsgSeeAlso:        "Siehe auch",
+	cli.MsgArgumentColumn: "Argument",
+	cli.MsgOptionColumn:   "Option",
+	cli.MsgCommandColumn:  "Befehl",
+	cli.MsgTypeColumn:     "Typ",
+	cli.MsgCharColumn:     "Zeichen",
+	cli.MsgOptionalColumn: "Optional",
+	cli.MsgShortcutColumn: "Kurzform",
+	cli.MsgYes:            "ja",
+	cli.MsgNo:             "nein",
+}
+
+func setupMessagesApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithArg(cli.NewArg("depth", "history depth").WithType(cli.TypeInt).AsOptional()).
+		WithOption(cli.NewOption("force", "Force").WithChar('f').WithType(cli.TypeBool).AsDeprecated("", "--discard")).
+		WithAction(func(args []string, options map[string]string) int {
+			return 0
+		})
+	return cli.New("git tool").
+		WithCommand(co).
+		WithCommand(cli.NewCommand("co", "Check out a branch").AsDeprecated("", "checkout")).
+		WithCatalog("de", german)
+}
+
+func runMessagesApp(a cli.App, vars map[string]string, args ...string) (int, string) {
+	w := &stringwriter{}
+	env := cli.Env{Streams: cli.Streams{In: strings.NewReader(""), Out: w, Err: w}, LookupEnv: lookupMap(vars)}
+	code := a.RunEnv(context.Background(), append([]string{"git"}, args...), env)
+	return code, w.str
+}
+
+func TestApp_Run_WithLanguage_ok(t *testing.T) {
+	code, out := runMessagesApp(setupMessagesApp().WithLanguage("de"), nil, "checkout", "--foo")
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "Fehler: unbekannte Option --foo\nAufruf: git checkout [--force] <branch> [depth]\n", out)
+}
+
+func TestCatalog_Complete_ok(t *testing.T) {
+	for id := range cli.English {
+		if _, ok := german.Message(id); !ok {
+			t.Errorf("expected the test catalog to translate %s", id)
+		}
+	}
+}
+
+func TestApp_Run_MissingMessageFallsBackToEnglish_ok(t *testing.T) {
+	partial := cli.MapCatalog{cli.MsgFatal: "Fehler: %v"}
+	a := setupMessagesApp().WithCatalog("de", partial).WithLanguage("de")
+	code, out := runMessagesApp(a, nil, "checkout", "master", "x")
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "Fehler: argument depth must be an integer value, found x\nusage: git checkout [--force] <branch> [depth]\n", out)
+}
+
+func TestApp_Run_LocalisedWrappedError_ok(t *testing.T) {
+	a := setupMessagesApp().WithLanguage("de").
+		WithCommand(cli.NewCommand("sh", "Run a git command").
+			WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+				_, err := cli.ParseArgs(setupMessagesApp(), []string{"git", "checkout", "--foo"})
+				return fmt.Errorf("sh: %w", err)
+			}))
+	code, out := runMessagesApp(a, nil, "sh")
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "Fehler: sh: unbekannte Option --foo\n", out)
+}
+
+func TestApp_Run_LanguageFromEnv_ok(t *testing.T) {
+	tests := []struct {
+		vars     map[string]string
+		expected string
+	}{
+		{map[string]string{"LANG": "de_DE.UTF-8"}, "Fehler: erforderliches Argument branch fehlt\n"},
+		{map[string]string{"LC_MESSAGES": "de_AT"}, "Fehler: erforderliches Argument branch fehlt\n"},
+		{map[string]string{"LANG": "de_DE", "LC_ALL": "C"}, "fatal: missing required argument branch\n"},
+		{map[string]string{"LANG": "fr_FR.UTF-8"}, "fatal: missing required argument branch\n"},
+	}
+	for _, test := range tests {
+		vars, expected := test.vars, test.expected
+		code, out := runMessagesApp(setupMessagesApp(), vars, "checkout")
+		assertAppRunOk(t, 1, code)
+		if !strings.HasPrefix(out, expected) {
+			t.Errorf("expected %q for %v, found %q", expected, vars, out)
+		}
+	}
+}
+
+func TestApp_Run_LocalisedWarning_ok(t *testing.T) {
+	code, out := runMessagesApp(setupMessagesApp(), map[string]string{"LANG": "de"}, "co")
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "Warnung: Befehl co ist veraltet, stattdessen checkout verwenden\n", out[:strings.Index(out, "\n")+1])
+}
+
+func TestApp_Run_LocalisedUsage_ok(t *testing.T) {
+	code, out := runMessagesApp(setupMessagesApp(), map[string]string{"LANG": "de_DE.UTF-8"}, "checkout", "--help")
+	assertAppRunOk(t, 0, code)
+	expected := `git checkout [--force] <branch> [depth]
+
+Beschreibung:
+    Check out a branch
+
+Argumente:
+    branch        branch to checkout
+    depth         history depth, optional
+
+Optionen:
+    -f, --force   Force, veraltet, stattdessen --discard
+`
+	assertAppUsageOk(t, expected, out)
+}
+
+func TestParseError_Error_English_ok(t *testing.T) {
+	_, _, _, err := setupMessagesApp().WithLanguage("de").Parse([]string{"git", "checkout", "--foo"})
+	assertAppUsageOk(t, "unknown option --foo", err.Error())
+}
+
+func TestTemplateRenderer_Message_ok(t *testing.T) {
+	r, err := cli.NewTemplateRenderer(`{{.Message "options"}}:{{range .Options}} --{{.Key}}{{end}}` + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, out := runMessagesApp(setupMessagesApp().WithLanguage("de").WithUsageRenderer(r), nil, "checkout", "--help")
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "Optionen: --force\n", out)
+}
+
+func TestTemplateRenderer_LocalisedDeprecation_ok(t *testing.T) {
+	r, err := cli.NewTemplateRenderer(`{{range .Options}}--{{.Key}}{{deprecation .}}{{end}}` + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, out := runMessagesApp(setupMessagesApp().WithLanguage("de").WithUsageRenderer(r), nil, "checkout", "--help")
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "--force, veraltet, stattdessen --discard\n", out)
+}
+
+func TestManPage_Localised_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.ManPage(setupMessagesApp().WithLanguage("de"), []string{"git", "checkout"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := `.TH "GIT\-CHECKOUT" "1" "" "" "git"
+.SH BEZEICHNUNG
+git\-checkout \- Check out a branch
+.SH ÜBERSICHT
+.B git checkout
+[\-\-force] <branch> [depth]
+.SH BESCHREIBUNG
+Check out a branch
+.SH ARGUMENTE
+.TP
+\fBbranch\fR
+branch to checkout
+.TP
+\fBdepth\fR
+history depth, optional
+.SH OPTIONEN
+.TP
+\fB\-f\fR, \fB\-\-force\fR
+Force, veraltet, stattdessen \-\-discard
+.SH SIEHE AUCH
+.BR git (1)
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestMarkdown_Localised_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.Markdown(setupMessagesApp().WithLanguage("de"), []string{"git"}, w); err != nil {
+		t.Fatal(err)
+	}
+	expected := "# git\n\ngit tool\n\n## Übersicht\n\n```\ngit\n```\n" + `
+## Unterbefehle
+
+| Befehl | Kurzform | Beschreibung |
+|---|---|---|
+| [` + "`git checkout`" + `](git-checkout.md) |  | Check out a branch |
+| [` + "`git co`" + `](git-co.md) |  | Check out a branch, veraltet, stattdessen checkout |
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestManPage_IgnoresEnvironment_ok(t *testing.T) {
+	lang, ok := os.LookupEnv("LC_ALL")
+	os.Setenv("LC_ALL", "de_DE.UTF-8")
+	defer func() {
+		if ok {
+			os.Setenv("LC_ALL", lang)
+		} else {
+			os.Unsetenv("LC_ALL")
+		}
+	}()
+	w := &stringwriter{}
+	if err := cli.ManPage(setupMessagesApp(), []string{"git", "checkout"}, w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.str, ".SH OPTIONS\n") || !strings.Contains(w.str, "Force, deprecated, use \\-\\-discard") {
+		t.Errorf("expected English man page, found %q", w.str)
+	}
+}
+
+func TestApp_Run_LocalisedResponseFileError_ok(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "dev\n-f 'master\n"})
+	defer os.RemoveAll(dir)
+
+	fname := filepath.Join(dir, "args.txt")
+	a := setupMessagesApp().WithLanguage("de").WithResponseFiles(true)
+	code, out := runMessagesApp(a, nil, "checkout", "@"+fname)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "Fehler: "+fname+":2: nicht geschlossenes Anführungszeichen '\n", out[:strings.Index(out, "\n")+1])
+}
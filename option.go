@@ -23,6 +23,10 @@ package cli
 //
 // Options can be used at any position after the command, arbitrarily intermixed with positional arguments.
 // In contrast to positional arguments the order of options is not preserved.
+//
+// Options are persistent by default, that is permitted for the application or command they are attached to
+// and all its sub-commands. Local options are permitted for the application or command they are attached
+// to only and their keys can be reused by sub-commands.
 type Option interface {
 	// Key returns the complete key of an option (used with the -- notation), required.
 	Key() string
@@ -33,11 +37,36 @@ type Option interface {
 	// Type returns the option type (string by default) to be used to decide if a value is required and for
 	// value validation.
 	Type() ValueType
+	// Deprecated specifies if the option is deprecated, i.e. still accepted but a warning is issued.
+	Deprecated() bool
+	// Deprecation returns the deprecation notice and the suggested replacement, both may be empty.
+	Deprecation() (notice, replacement string)
+	// Hidden specifies if the option is excluded from the usage (it is still accepted).
+	Hidden() bool
+	// Local specifies if the option is permitted for the application or command it is attached to only,
+	// rather than being inherited by all sub-commands.
+	Local() bool
+	// Completer returns the function completing option values, nil if not set.
+	Completer() Completer
+	// Group returns the name of the group the option is listed under in the usage, empty if none.
+	Group() string
 
 	// WithChar sets the char key for the option.
 	WithChar(char rune) Option
 	// WithType sets the option value type.
 	WithType(ft ValueType) Option
+	// AsDeprecated marks the option as deprecated with an optional notice and replacement, e.g.
+	// `--force`, to be output in the warning issued when the option is used and in the usage.
+	AsDeprecated(notice, replacement string) Option
+	// AsHidden excludes the option from the usage, e.g. for internal or debugging options.
+	AsHidden() Option
+	// AsLocal restricts the option to the application or command it is attached to.
+	AsLocal() Option
+	// WithCompleter sets the function completing option values in the dynamic shell completion.
+	WithCompleter(completer Completer) Option
+	// WithGroup assigns the option to a named group, e.g. `Networking`, listed under its own heading
+	// in the usage, see Command.WithCategory.
+	WithGroup(group string) Option
 }
 
 // NewOption creates a new option with a given key and description.
@@ -50,10 +79,15 @@ func NewOption(key, descr string) Option {
 }
 
 type option struct {
-	key   string
-	char  rune
-	descr string
-	tp    ValueType
+	key    string
+	char   rune
+	descr  string
+	tp     ValueType
+	depr   *deprecation
+	hidden bool
+	local  bool
+	compl  Completer
+	group  string
 }
 
 func (f option) Key() string {
@@ -85,3 +119,52 @@ func (f option) WithType(tp ValueType) Option {
 	f.tp = tp
 	return f
 }
+
+func (f option) Deprecated() bool {
+	return f.depr != nil
+}
+
+func (f option) Deprecation() (notice, replacement string) {
+	return f.depr.get()
+}
+
+func (f option) AsDeprecated(notice, replacement string) Option {
+	f.depr = &deprecation{notice: notice, replacement: replacement}
+	return f
+}
+
+func (f option) Hidden() bool {
+	return f.hidden
+}
+
+func (f option) AsHidden() Option {
+	f.hidden = true
+	return f
+}
+
+func (f option) Local() bool {
+	return f.local
+}
+
+func (f option) AsLocal() Option {
+	f.local = true
+	return f
+}
+
+func (f option) Completer() Completer {
+	return f.compl
+}
+
+func (f option) WithCompleter(completer Completer) Option {
+	f.compl = completer
+	return f
+}
+
+func (f option) Group() string {
+	return f.group
+}
+
+func (f option) WithGroup(group string) Option {
+	f.group = group
+	return f
+}
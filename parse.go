@@ -4,7 +4,6 @@
 package cli
 
 import (
-	"fmt"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -12,42 +11,99 @@ import (
 )
 
 const (
-	helpKey  = "help"
-	helpChar = 'h'
-	trueStr  = "true"
+	helpKey    = "help"
+	helpAllKey = "help-all"
+	helpChar   = 'h'
+	trueStr    = "true"
 )
 
+// ParseResult captures the outcome of parsing the original application arguments.
+type ParseResult struct {
+	// Invocation is the command invocation path: application -> first level command -> second level
+	// command etc.
+	Invocation []string
+	// Args lists positional arguments matching the command being invoked (the last one in the invocation
+	// path). Unless the command declares passthrough arguments, these include arguments following `--`.
+	Args []string
+	// Passthrough lists arguments following `--` verbatim, see PassthroughFrom.
+	Passthrough []string
+	// Opts maps complete option keys to their values for options matching one of the invocation path
+	// elements.
+	Opts map[string]string
+}
+
 // Parse parses the original application arguments into the command invocation path (application ->
 // first level command -> second level command etc.), a list of validated positional arguments matching
 // the command being invoked (the last one in the invocation path) and a map of validated options
 // matching one of the invocation path elements, from the top application down to the command being invoked.
 // An error is returned if a command is not found or arguments or options are invalid. In case of an error,
 // the invocation path is normally also computed and returned (the content of arguments and options is not
-// guaranteed). See `App.parse`
+// guaranteed). Arguments following `--` are not included for commands declaring passthrough arguments,
+// see ParseArgs. See `App.parse`
 func Parse(a App, appargs []string) (invocation []string, args []string, opts map[string]string, err error) {
-	_, appname := path.Split(appargs[0])
-	// Remove the path and extension of the executable
-	appname = filepath.Base(appname)
-	appname = strings.TrimSuffix(appname, filepath.Ext(appname))
+	res, err := ParseArgs(a, appargs)
+	return res.Invocation, res.Args, res.Opts, err
+}
 
-	invocation, argsAndOpts, expArgs, accptOpts := evalCommand(a, appargs[1:])
-	invocation = append([]string{appname}, invocation...)
+// ParseArgs parses the original application arguments same as Parse, but keeps arguments following `--`
+// separately from positional arguments. For commands declaring passthrough arguments these are excluded
+// from the validation of positional arguments and are validated against the type of the passthrough
+// arguments instead. Errors on invalid arguments, options and response files carry a ParseError locating
+// the offending argument, e.g. UnknownOptionError, MissingArgumentError or ResponseFileError, which is
+// obtained with errors.As and a Located target.
+func ParseArgs(a App, appargs []string) (res ParseResult, err error) {
+	return parseArgs(a, appargs, "")
+}
+
+// parseArgs parses the application arguments resolving relative paths of response files against dir,
+// the working directory of the process if empty.
+func parseArgs(a App, appargs []string, dir string) (res ParseResult, err error) {
+	res.Invocation = []string{appname(appargs[0])}
+	appargs = appargs[1:]
+	if a.ResponseFiles() {
+		if appargs, err = expandResponseFiles(appargs, res.Invocation, dir); err != nil {
+			return res, err
+		}
+	}
 
-	if args, opts, err = splitArgsAndOpts(argsAndOpts, accptOpts); err == nil {
-		if _, ok := opts["help"]; !ok {
-			if err = assertArgs(expArgs, args); err == nil {
-				err = assertOpts(accptOpts, opts)
+	invocation, argsAndOpts := evalCommand(a, appargs)
+	res.Invocation = append(res.Invocation, invocation...)
+	// never fails for the invocation path computed by evalCommand
+	sc, _ := resolve(a, res.Invocation)
+	accptOpts := sc.permitted()
+
+	var pos positions
+	vkey, vchar := versionFlags(a, accptOpts)
+	if res.Args, res.Passthrough, res.Opts, pos, err = splitArgsAndOpts(argsAndOpts, accptOpts, sc.interspersed, vkey, vchar); err == nil {
+		if sc.passthrough == nil {
+			res.Args = append(res.Args, res.Passthrough...)
+		}
+		_, help := res.Opts[helpKey]
+		if !help && !versionRequested(a, sc, res.Opts) {
+			if err = assertArgs(sc.args, res.Args, pos.args); err == nil && sc.passthrough != nil {
+				err = assertPassthrough(sc.passthrough, res.Passthrough, pos.args[len(res.Args):])
+			}
+			if err == nil {
+				err = assertOpts(accptOpts, res.Opts, pos.opts)
 			}
 		}
 	}
-	return invocation, args, opts, err
+	// argsAndOpts follow the executable and the commands in appargs
+	locate(err, argsAndOpts, 1+len(appargs)-len(argsAndOpts), res.Invocation)
+	return res, err
 }
 
-func evalCommand(a App, appargs []string) (invocation []string, argsAndOpts []string, expArgs []Arg, accptOpts []Option) {
+// appname returns the application name given the path to the executable.
+func appname(executable string) string {
+	_, name := path.Split(executable)
+	// Remove the path and extension of the executable
+	name = filepath.Base(name)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func evalCommand(a App, appargs []string) (invocation []string, argsAndOpts []string) {
 	invocation = []string{}
 	argsAndOpts = appargs
-	expArgs = a.Args()
-	accptOpts = a.Options()
 
 	cmds2check := a.Commands()
 	for i, arg := range appargs {
@@ -56,8 +112,6 @@ func evalCommand(a App, appargs []string) (invocation []string, argsAndOpts []st
 			if cmd.Key() == arg || cmd.Shortcut() == arg {
 				invocation = append(invocation, cmd.Key())
 				argsAndOpts = appargs[i+1:]
-				expArgs = cmd.Args()
-				accptOpts = append(accptOpts, cmd.Options()...)
 
 				cmds2check = cmd.Commands()
 				matched = true
@@ -68,30 +122,122 @@ func evalCommand(a App, appargs []string) (invocation []string, argsAndOpts []st
 			break
 		}
 	}
-	return invocation, argsAndOpts, expArgs, accptOpts
+	return invocation, argsAndOpts
+}
+
+// scope captures the definitions applicable to the last element of an invocation path.
+type scope struct {
+	// path lists the commands along the invocation path excluding the application itself
+	path  []Command
+	descr string
+	args  []Arg
+	// opts lists the options of the invoked command (or application) itself
+	opts []Option
+	// global lists the options inherited from the ancestors of the invoked command
+	global []Option
+	cmds   []Command
+	// interspersed specifies if options can follow positional arguments
+	interspersed bool
+	// passthrough defines the arguments following `--`, if declared
+	passthrough Arg
+	examples    []Example
+}
+
+// permitted returns all options permitted for the invoked command, inherited ones first.
+func (s *scope) permitted() []Option {
+	return append(append([]Option{}, s.global...), s.opts...)
+}
+
+// resolve walks the invocation path (as returned by Parse) down the command tree collecting the
+// definitions applicable to the last element of the path.
+func resolve(a App, invocation []string) (*scope, error) {
+	if len(invocation) < 1 {
+		return nil, errorf(MsgInvalidInvocation, invocation)
+	}
+	s := &scope{descr: a.Description(), args: a.Args(), opts: a.Options(), cmds: a.Commands(),
+		interspersed: a.Interspersed(), passthrough: a.Passthrough(), examples: a.Examples()}
+	for _, key := range invocation[1:] {
+		matched := false
+		for _, cmd := range s.cmds {
+			if cmd.Key() == key {
+				s.path = append(s.path, cmd)
+				s.descr = cmd.Description()
+				s.args = cmd.Args()
+				s.global = inherit(s.global, s.opts)
+				s.opts = cmd.Options()
+				s.cmds = cmd.Commands()
+				s.interspersed = cmd.Interspersed()
+				s.passthrough = cmd.Passthrough()
+				s.examples = cmd.Examples()
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return s, errorf(MsgInvalidInvocation, invocation)
+		}
+	}
+	return s, nil
 }
 
-func splitArgsAndOpts(appargs []string, accptOpts []Option) (args []string, opts map[string]string, err error) {
+// inherit appends non-local options to the inherited ones.
+func inherit(global []Option, opts []Option) []Option {
+	res := append([]Option{}, global...)
+	for _, opt := range opts {
+		if !opt.Local() {
+			res = append(res, opt)
+		}
+	}
+	return res
+}
+
+// positions maps parsed values to the indices of the arguments they originate from.
+type positions struct {
+	// args lists the indices of positional arguments followed by those of passthrough arguments
+	args []int
+	// opts maps complete option keys to the indices of the arguments carrying their values
+	opts map[string]int
+}
+
+// splitArgsAndOpts splits the arguments into positional and passthrough arguments and options, vkey and
+// vchar enable the built-in `--version` and `-V` flags, respectively.
+func splitArgsAndOpts(appargs []string, accptOpts []Option, interspersed, vkey, vchar bool) (args []string, passthrough []string, opts map[string]string, pos positions, err error) {
 	opts = make(map[string]string)
+	pos.opts = make(map[string]int)
+	var passidx []int
 
-	passthrough := false
+	dashdash := false
 	danglingOpt := ""
-	for _, arg := range appargs {
-		if arg == "--" {
-			passthrough = true
+	danglingIdx := 0
+	for i, arg := range appargs {
+		if arg == "--" && !dashdash {
+			dashdash = true
 			continue
 		}
 
 		if danglingOpt != "" {
 			opts[danglingOpt] = arg
+			pos.opts[danglingOpt] = i
 			danglingOpt = ""
 			continue
 		}
 
-		if !passthrough && strings.HasPrefix(arg, "--") {
+		if dashdash {
+			passthrough = append(passthrough, arg)
+			passidx = append(passidx, i)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
 			arg = arg[2:]
 			if arg == helpKey {
-				return nil, map[string]string{helpKey: trueStr}, nil
+				return nil, nil, map[string]string{helpKey: trueStr}, positions{}, nil
+			}
+			if arg == helpAllKey {
+				return nil, nil, map[string]string{helpKey: trueStr, helpAllKey: trueStr}, positions{}, nil
+			}
+			if vkey && arg == versionKey {
+				return nil, nil, map[string]string{versionKey: trueStr}, positions{}, nil
 			}
 			parts := strings.Split(arg, "=")
 			key := parts[0]
@@ -102,106 +248,142 @@ func splitArgsAndOpts(appargs []string, accptOpts []Option) (args []string, opts
 						if len(parts) == 1 {
 							opts[accptOpt.Key()] = trueStr
 						} else {
-							return args, opts, fmt.Errorf("boolean options have true assigned implicitly, found value for --%s", key)
+							err = &InvalidValueError{ParseError: ParseError{Index: i}, Key: key,
+								Value: strings.Join(parts[1:], "="), Type: TypeBool, Option: true}
+							return args, passthrough, opts, pos, err
 						}
 					} else if len(parts) >= 2 {
 						opts[accptOpt.Key()] = strings.Join(parts[1:], "=") // permit = in values
 					} else {
-						return args, opts, fmt.Errorf("missing value for option --%s", key)
+						return args, passthrough, opts, pos, &MissingValueError{ParseError: ParseError{Index: i}, Key: key}
 					}
+					pos.opts[accptOpt.Key()] = i
 					matched = true
 					break
 				}
 			}
 			if !matched {
-				return args, opts, fmt.Errorf("unknown option --%s", key)
+				return args, passthrough, opts, pos, &UnknownOptionError{ParseError: ParseError{Index: i}, Key: key}
 			}
 			continue
 		}
 
-		if !passthrough && strings.HasPrefix(arg, "-") {
+		if strings.HasPrefix(arg, "-") {
 			arg = arg[1:]
 
-			for i, char := range arg {
+			for j, char := range arg {
 				if char == helpChar {
-					return nil, map[string]string{helpKey: trueStr}, nil
+					return nil, nil, map[string]string{helpKey: trueStr}, positions{}, nil
+				}
+				if vchar && char == versionChar {
+					return nil, nil, map[string]string{versionKey: trueStr}, positions{}, nil
 				}
 				matched := false
 				for _, accptOpt := range accptOpts {
 					if accptOpt.CharKey() == char {
 						if accptOpt.Type() == TypeBool {
 							opts[accptOpt.Key()] = trueStr
-						} else if i == len(arg)-1 {
+							pos.opts[accptOpt.Key()] = i
+						} else if j == len(arg)-1 {
 							danglingOpt = accptOpt.Key()
+							danglingIdx = i
 						} else {
-							return args, opts, fmt.Errorf("non-boolean flag -%v in non-terminal position", string(char))
+							return args, passthrough, opts, pos, &NonTerminalFlagError{ParseError: ParseError{Index: i}, Key: string(char)}
 						}
 						matched = true
 						break
 					}
 				}
 				if !matched {
-					return args, opts, fmt.Errorf("unknown flag -%v", string(char))
+					return args, passthrough, opts, pos, &UnknownOptionError{ParseError: ParseError{Index: i}, Key: string(char), Flag: true}
 				}
 			}
 			continue
 		}
 
 		args = append(args, arg)
+		pos.args = append(pos.args, i)
+		if !interspersed {
+			// stop at the first positional argument passing the remaining ones verbatim
+			args = append(args, appargs[i+1:]...)
+			for j := i + 1; j < len(appargs); j++ {
+				pos.args = append(pos.args, j)
+			}
+			break
+		}
 	}
+	pos.args = append(pos.args, passidx...)
 	if danglingOpt != "" {
-		return args, opts, fmt.Errorf("dangling option --%s", danglingOpt)
+		return args, passthrough, opts, pos, &DanglingOptionError{ParseError: ParseError{Index: danglingIdx}, Key: danglingOpt}
 	}
-	return args, opts, nil
+	return args, passthrough, opts, pos, nil
 }
 
-func assertArgs(expected []Arg, actual []string) error {
+// assertArgs validates the positional arguments against their definition, argidx lists the indices of
+// the arguments they originate from.
+func assertArgs(expected []Arg, actual []string, argidx []int) error {
 	if len(expected) == 0 || !expected[len(expected)-1].Optional() {
 		if len(expected) > len(actual) {
-			return fmt.Errorf("missing required argument %v", expected[len(actual)].Key())
+			return &MissingArgumentError{ParseError: ParseError{Index: -1}, Key: expected[len(actual)].Key()}
 		} else if len(expected) < len(actual) {
-			return fmt.Errorf("unknown arguments %v", actual[len(expected):])
+			return &UnknownArgumentsError{ParseError: ParseError{Index: argidx[len(expected)]}, Args: actual[len(expected):]}
 		}
 	}
 	for i, e := range expected {
 		if len(actual) < i+1 {
 			if !e.Optional() {
-				return fmt.Errorf("missing required argument %s", e.Key())
+				return &MissingArgumentError{ParseError: ParseError{Index: -1}, Key: e.Key()}
 			}
 			break
 		}
-		arg := actual[i]
-		switch e.Type() {
-		case TypeBool:
-			if _, err := strconv.ParseBool(arg); err != nil {
-				return fmt.Errorf("argument %s must be a boolean value, found %v", e.Key(), arg)
-			}
-		case TypeInt:
-			if _, err := strconv.ParseInt(arg, 10, 64); err != nil {
-				return fmt.Errorf("argument %s must be an integer value, found %v", e.Key(), arg)
-			}
-		case TypeNumber:
-			if _, err := strconv.ParseFloat(arg, 64); err != nil {
-				return fmt.Errorf("argument %s must be a number, found %v", e.Key(), arg)
-			}
+		if arg := actual[i]; !validValue(e.Type(), arg) {
+			return &InvalidValueError{ParseError: ParseError{Index: argidx[i]}, Key: e.Key(), Value: arg, Type: e.Type()}
+		}
+	}
+	return nil
+}
+
+// assertPassthrough validates the arguments following `--` against the type of the passthrough arguments,
+// argidx lists the indices of the arguments they originate from.
+func assertPassthrough(expected Arg, actual []string, argidx []int) error {
+	for i, arg := range actual {
+		if !validValue(expected.Type(), arg) {
+			return &InvalidValueError{ParseError: ParseError{Index: argidx[i]}, Key: expected.Key(), Value: arg, Type: expected.Type()}
 		}
 	}
 	return nil
 }
 
-func assertOpts(permitted []Option, actual map[string]string) error {
+// validValue checks if the value of a positional argument can be parsed as the given type.
+func validValue(tp ValueType, value string) bool {
+	var err error
+	switch tp {
+	case TypeBool:
+		_, err = strconv.ParseBool(value)
+	case TypeInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case TypeNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	return err == nil
+}
+
+// assertOpts validates the option values against their definition, optidx maps the option keys to the
+// indices of the arguments carrying their values.
+func assertOpts(permitted []Option, actual map[string]string, optidx map[string]int) error {
 	for key, value := range actual {
 		for _, p := range permitted {
 			if p.Key() == key {
+				var err error
 				switch p.Type() {
 				case TypeInt:
-					if _, err := strconv.ParseInt(value, 10, 64); err != nil {
-						return fmt.Errorf("option --%s must be given an integer value, found %v", p.Key(), value)
-					}
+					_, err = strconv.ParseInt(value, 10, 64)
 				case TypeNumber:
-					if _, err := strconv.ParseFloat(value, 64); err != nil {
-						return fmt.Errorf("option --%s must must be given a number, found %v", p.Key(), value)
-					}
+					_, err = strconv.ParseFloat(value, 64)
+				}
+				if err != nil {
+					return &InvalidValueError{ParseError: ParseError{Index: optidx[key]}, Key: key, Value: value,
+						Type: p.Type(), Option: true}
 				}
 				break
 			}
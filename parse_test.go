@@ -4,6 +4,7 @@
 package cli_test
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
@@ -41,6 +42,31 @@ func setuParseApp() cli.App {
 		WithCommand(rmt)
 }
 
+func setupPassthroughApp() cli.App {
+	run := cli.NewCommand("run", "run a script").
+		WithArg(cli.NewArg("script", "script to run")).
+		WithArg(cli.NewArg("mode", "mode").AsOptional()).
+		WithPassthrough(cli.NewArg("extra", "script arguments"))
+
+	sum := cli.NewCommand("sum", "sum numbers").
+		WithPassthrough(cli.NewArg("numbers", "numbers to sum").WithType(cli.TypeNumber))
+
+	return cli.New("git tool").
+		WithCommand(run).
+		WithCommand(sum)
+}
+
+func setupWrapperApp() cli.App {
+	exec := cli.NewCommand("exec", "execute a command").
+		WithInterspersed(false).
+		WithArg(cli.NewArg("cmd", "command to execute")).
+		WithArg(cli.NewArg("args", "command arguments").AsOptional()).
+		WithOption(cli.NewOption("quiet", "Quiet").WithChar('q').WithType(cli.TypeBool))
+
+	return cli.New("git tool").
+		WithCommand(exec)
+}
+
 func TestApp_Parse_DropsPathFromAppName_Ok(t *testing.T) {
 	invocation, args, opts, err := cli.Parse(setuParseApp(), []string{"~/some/path/git", "checkout", "dev"})
 	assertAppParseOk(t, "[git checkout] [dev] map[]", invocation, args, opts, err)
@@ -232,6 +258,67 @@ func TestApp_Parse_HelpFlagInMultichar_Ok(t *testing.T) {
 	assertAppParseOk(t, "[git checkout] [] map[help:true]", invocation, args, opts, err)
 }
 
+func TestApp_Parse_LocalOptionOnOwnCommand_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupLocalOptsApp(), []string{"git", "remote", "--dry", "--verbose"})
+	assertAppParseOk(t, "[git remote] [] map[dry:true verbose:true]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_LocalOptionKeyReusedBySubCommand_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupLocalOptsApp(), []string{"git", "remote", "add", "-n"})
+	assertAppParseOk(t, "[git remote add] [] map[dry:true]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_LocalOptionOfAncestor_Error(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupLocalOptsApp(), []string{"git", "remote", "--init"})
+	assertAppParseError(t, "[git remote] [] map[]", "unknown option --init", invocation, args, opts, err)
+}
+
+func TestApp_Parse_NonInterspersed_StopsAtFirstArg_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupWrapperApp(), []string{"git", "exec", "-q", "ls", "-la", "--", "--quiet", "-h"})
+	assertAppParseOk(t, "[git exec] [ls -la -- --quiet -h] map[quiet:true]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_NonInterspersed_DashDashBeforeFirstArg_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupWrapperApp(), []string{"git", "exec", "--", "-q", "-la"})
+	assertAppParseOk(t, "[git exec] [-q -la] map[]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_NonInterspersed_HelpBeforeFirstArg_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupWrapperApp(), []string{"git", "exec", "-h", "ls"})
+	assertAppParseOk(t, "[git exec] [] map[help:true]", invocation, args, opts, err)
+}
+
+func TestApp_ParseArgs_PassthroughKeptSeparately_Ok(t *testing.T) {
+	res, err := cli.ParseArgs(setuParseApp(), []string{"git", "remote", "add", "origin", "1", "3.14", "true", "false", "--", "-j", "--", "doit"})
+	assertAppParseOk(t, "[git remote add] [origin 1 3.14 true false -j -- doit] map[]", res.Invocation, res.Args, res.Opts, err)
+	assertAppParseOk(t, "[git remote add] [-j -- doit] map[]", res.Invocation, res.Passthrough, res.Opts, err)
+}
+
+func TestApp_ParseArgs_DeclaredPassthroughNotValidatedAsArgs_Ok(t *testing.T) {
+	res, err := cli.ParseArgs(setupPassthroughApp(), []string{"git", "run", "a.sh", "--", "-x", "1", "2"})
+	assertAppParseOk(t, "[git run] [a.sh] map[]", res.Invocation, res.Args, res.Opts, err)
+	assertAppParseOk(t, "[git run] [-x 1 2] map[]", res.Invocation, res.Passthrough, res.Opts, err)
+}
+
+func TestApp_Parse_DeclaredPassthroughExcluded_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setupPassthroughApp(), []string{"git", "run", "a.sh", "fast", "--", "-x"})
+	assertAppParseOk(t, "[git run] [a.sh fast] map[]", invocation, args, opts, err)
+}
+
+func TestApp_ParseArgs_PassthroughTypeValidated_Ok(t *testing.T) {
+	res, err := cli.ParseArgs(setupPassthroughApp(), []string{"git", "sum", "--", "1", "2.5"})
+	assertAppParseOk(t, "[git sum] [1 2.5] map[]", res.Invocation, res.Passthrough, res.Opts, err)
+}
+
+func TestApp_ParseArgs_PassthroughTypeValidated_Error(t *testing.T) {
+	res, err := cli.ParseArgs(setupPassthroughApp(), []string{"git", "sum", "--", "1", "x"})
+	assertAppParseError(t, "[git sum] [] map[]", "argument numbers must be a number, found x", res.Invocation, res.Args, res.Opts, err)
+	var ive *cli.InvalidValueError
+	if !errors.As(err, &ive) || ive.Index != 4 || ive.Token != "x" {
+		t.Errorf("expected invalid value x at index 4, found %v", err)
+	}
+}
+
 func assertAppParseOk(t *testing.T, expected string, invocation []string, args []string, opts map[string]string, err error) {
 	if err == nil {
 		optkeys := []string{}
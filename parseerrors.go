@@ -0,0 +1,221 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+// ParseError captures the location of an error in the application arguments. It is embedded into all
+// errors returned from Parse and ParseArgs on invalid arguments, options and response files, e.g.
+// UnknownOptionError, which can be distinguished with errors.As. The location of any of these is
+// obtained with errors.As and a Located target.
+type ParseError struct {
+	// Invocation is the command invocation path the error occurred for, see ParseResult.
+	Invocation []string
+	// Token is the offending application argument, empty if the error refers to a missing argument.
+	Token string
+	// Index is the index of the offending token in the application arguments, the executable being
+	// at index 0, -1 if the error refers to a missing argument. With response files enabled the index
+	// refers to the arguments after expansion, except for ResponseFileError referring to the `@path`
+	// argument itself.
+	Index int
+}
+
+// Location returns the location, promoted to the errors the location is embedded into, see Located.
+func (e ParseError) Location() ParseError {
+	return e
+}
+
+func (e *ParseError) location() *ParseError {
+	return e
+}
+
+// Located defines errors carrying the location of the error in the application arguments, implemented by
+// all errors embedding a ParseError, e.g. `var loc cli.Located; if errors.As(err, &loc) {...}`.
+type Located interface {
+	error
+	Location() ParseError
+}
+
+// locatable defines errors carrying a ParseError.
+type locatable interface {
+	localised
+	location() *ParseError
+}
+
+// UnknownOptionError is returned for options and flags not permitted for the invoked command.
+type UnknownOptionError struct {
+	ParseError
+	// Key is the complete key of the option or the char key of the flag.
+	Key string
+	// Flag specifies if the option was given by its char key, e.g. `-f`.
+	Flag bool
+}
+
+func (e *UnknownOptionError) Error() string {
+	return e.message(English)
+}
+
+func (e *UnknownOptionError) message(c Catalog) string {
+	if e.Flag {
+		return msg(c, MsgUnknownFlag, e.Key)
+	}
+	return msg(c, MsgUnknownOption, e.Key)
+}
+
+// MissingValueError is returned for non-boolean options given with their complete key, but no value.
+type MissingValueError struct {
+	ParseError
+	// Key is the complete key of the option.
+	Key string
+}
+
+func (e *MissingValueError) Error() string {
+	return e.message(English)
+}
+
+func (e *MissingValueError) message(c Catalog) string {
+	return msg(c, MsgMissingValue, e.Key)
+}
+
+// DanglingOptionError is returned for non-boolean flags given last with no value following.
+type DanglingOptionError struct {
+	ParseError
+	// Key is the complete key of the option.
+	Key string
+}
+
+func (e *DanglingOptionError) Error() string {
+	return e.message(English)
+}
+
+func (e *DanglingOptionError) message(c Catalog) string {
+	return msg(c, MsgDanglingOption, e.Key)
+}
+
+// NonTerminalFlagError is returned for non-boolean flags joined with further flags, e.g. `-cv`
+// for a non-boolean `-c`.
+type NonTerminalFlagError struct {
+	ParseError
+	// Key is the char key of the flag.
+	Key string
+}
+
+func (e *NonTerminalFlagError) Error() string {
+	return e.message(English)
+}
+
+func (e *NonTerminalFlagError) message(c Catalog) string {
+	return msg(c, MsgNonTerminalFlag, e.Key)
+}
+
+// MissingArgumentError is returned if a required positional argument is missing.
+type MissingArgumentError struct {
+	ParseError
+	// Key is the key of the missing argument.
+	Key string
+}
+
+func (e *MissingArgumentError) Error() string {
+	return e.message(English)
+}
+
+func (e *MissingArgumentError) message(c Catalog) string {
+	return msg(c, MsgMissingArgument, e.Key)
+}
+
+// UnknownArgumentsError is returned for positional arguments exceeding those defined for the command.
+// The location refers to the first of these.
+type UnknownArgumentsError struct {
+	ParseError
+	// Args lists the arguments exceeding the definition.
+	Args []string
+}
+
+func (e *UnknownArgumentsError) Error() string {
+	return e.message(English)
+}
+
+func (e *UnknownArgumentsError) message(c Catalog) string {
+	return msg(c, MsgUnknownArguments, e.Args)
+}
+
+// InvalidValueError is returned for values of positional arguments and options not matching their type,
+// including values given to boolean options.
+type InvalidValueError struct {
+	ParseError
+	// Key is the key of the argument or the complete key of the option.
+	Key string
+	// Value is the offending value.
+	Value string
+	// Type is the type of the argument or option.
+	Type ValueType
+	// Option specifies if the value was given to an option rather than an argument.
+	Option bool
+}
+
+func (e *InvalidValueError) Error() string {
+	return e.message(English)
+}
+
+func (e *InvalidValueError) message(c Catalog) string {
+	if e.Option {
+		switch e.Type {
+		case TypeBool:
+			return msg(c, MsgBoolOptionValue, e.Key)
+		case TypeInt:
+			return msg(c, MsgIntOptionValue, e.Key, e.Value)
+		default:
+			return msg(c, MsgNumberOptionValue, e.Key, e.Value)
+		}
+	}
+	switch e.Type {
+	case TypeBool:
+		return msg(c, MsgBoolArgValue, e.Key, e.Value)
+	case TypeInt:
+		return msg(c, MsgIntArgValue, e.Key, e.Value)
+	default:
+		return msg(c, MsgNumberArgValue, e.Key, e.Value)
+	}
+}
+
+// ResponseFileError is returned for response files that cannot be read, reference each other cyclically
+// or contain malformed lines, see App.WithResponseFiles. The location refers to the `@path` argument the
+// file is referenced from, directly or through further response files.
+type ResponseFileError struct {
+	ParseError
+	// File is the path to the response file.
+	File string
+	// Line is the line in the file the error refers to, 0 if the error refers to the file as a whole.
+	Line int
+	// Err is the underlying error, a ResponseFileError for errors in files referenced from the file.
+	Err error
+}
+
+func (e *ResponseFileError) Error() string {
+	return e.message(English)
+}
+
+func (e *ResponseFileError) message(c Catalog) string {
+	if e.Line > 0 {
+		return msg(c, MsgResponseFileLine, e.File, e.Line, errmsg(c, e.Err))
+	}
+	return msg(c, MsgResponseFile, e.File, errmsg(c, e.Err))
+}
+
+func (e *ResponseFileError) Unwrap() error {
+	return e.Err
+}
+
+// locate completes the location of a parse error given the arguments the local index of the error
+// refers to, the offset of these in the application arguments and the invocation path.
+func locate(err error, argsAndOpts []string, offset int, invocation []string) {
+	if e, ok := err.(locatable); ok {
+		loc := e.location()
+		loc.Invocation = invocation
+		if loc.Index >= 0 && loc.Index < len(argsAndOpts) {
+			loc.Token = argsAndOpts[loc.Index]
+			loc.Index += offset
+		} else {
+			loc.Index = -1
+		}
+	}
+}
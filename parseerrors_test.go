@@ -0,0 +1,142 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func assertParseErrorLocation(t *testing.T, appargs []string, loc cli.ParseError, invocation, token string, index int) {
+	if strings.Join(loc.Invocation, " ") != invocation || loc.Token != token || loc.Index != index {
+		t.Errorf("%v: expected location [%s] %q at %d, found %v %q at %d", appargs, invocation, token, index,
+			loc.Invocation, loc.Token, loc.Index)
+	}
+}
+
+func TestParseArgs_UnknownOptionError_ok(t *testing.T) {
+	tests := []struct {
+		appargs []string
+		key     string
+		flag    bool
+		token   string
+		index   int
+	}{
+		{[]string{"git", "checkout", "--foo", "dev"}, "foo", false, "--foo", 2},
+		{[]string{"git", "co", "dev", "-bx"}, "x", true, "-bx", 3},
+	}
+	for _, test := range tests {
+		_, err := cli.ParseArgs(setuParseApp(), test.appargs)
+		var e *cli.UnknownOptionError
+		if !errors.As(err, &e) {
+			t.Errorf("%v: expected UnknownOptionError, found %v", test.appargs, err)
+			continue
+		}
+		if e.Key != test.key || e.Flag != test.flag {
+			t.Errorf("%v: unexpected error %+v", test.appargs, e)
+		}
+		assertParseErrorLocation(t, test.appargs, e.ParseError, "git checkout", test.token, test.index)
+	}
+}
+
+func TestParseArgs_MissingArgumentError_ok(t *testing.T) {
+	appargs := []string{"git", "remote", "add", "origin", "1"}
+	_, err := cli.ParseArgs(setuParseApp(), appargs)
+	var e *cli.MissingArgumentError
+	if !errors.As(err, &e) || e.Key != "pi" {
+		t.Fatalf("expected MissingArgumentError for pi, found %v", err)
+	}
+	assertAppUsageOk(t, "missing required argument pi", err.Error())
+	assertParseErrorLocation(t, appargs, e.ParseError, "git remote add", "", -1)
+}
+
+func TestParseArgs_UnknownArgumentsError_ok(t *testing.T) {
+	appargs := []string{"git", "checkout", "-b", "dev", "main", "next"}
+	_, err := cli.ParseArgs(setuParseApp(), appargs)
+	var e *cli.UnknownArgumentsError
+	if !errors.As(err, &e) || fmt.Sprint(e.Args) != "[main next]" {
+		t.Fatalf("expected UnknownArgumentsError, found %v", err)
+	}
+	assertParseErrorLocation(t, appargs, e.ParseError, "git checkout", "main", 4)
+}
+
+func TestParseArgs_InvalidValueError_ok(t *testing.T) {
+	tests := []struct {
+		appargs []string
+		key     string
+		value   string
+		option  bool
+		token   string
+		index   int
+		message string
+		inv     string
+	}{
+		{[]string{"git", "remote", "add", "origin", "x", "3.14", "true"}, "count", "x", false, "x", 4,
+			"argument count must be an integer value, found x", "git remote add"},
+		{[]string{"git", "co", "dev", "-c", "x"}, "count", "x", true, "x", 4,
+			"option --count must be given an integer value, found x", "git checkout"},
+		{[]string{"git", "co", "--pi=aaa", "dev"}, "pi", "aaa", true, "--pi=aaa", 2,
+			"option --pi must must be given a number, found aaa", "git checkout"},
+		{[]string{"git", "co", "--branch=yes", "dev"}, "branch", "yes", true, "--branch=yes", 2,
+			"boolean options have true assigned implicitly, found value for --branch", "git checkout"},
+	}
+	for _, test := range tests {
+		_, err := cli.ParseArgs(setuParseApp(), test.appargs)
+		var e *cli.InvalidValueError
+		if !errors.As(err, &e) {
+			t.Errorf("%v: expected InvalidValueError, found %v", test.appargs, err)
+			continue
+		}
+		if e.Key != test.key || e.Value != test.value || e.Option != test.option {
+			t.Errorf("%v: unexpected error %+v", test.appargs, e)
+		}
+		assertAppUsageOk(t, test.message, err.Error())
+		assertParseErrorLocation(t, test.appargs, e.ParseError, test.inv, test.token, test.index)
+	}
+}
+
+func TestParseArgs_OptionErrors_ok(t *testing.T) {
+	_, err := cli.ParseArgs(setuParseApp(), []string{"git", "checkout", "dev", "--fallback"})
+	var missing *cli.MissingValueError
+	if !errors.As(err, &missing) || missing.Key != "fallback" || missing.Index != 3 {
+		t.Errorf("expected MissingValueError, found %v", err)
+	}
+
+	_, err = cli.ParseArgs(setuParseApp(), []string{"git", "checkout", "dev", "-bf"})
+	var dangling *cli.DanglingOptionError
+	if !errors.As(err, &dangling) || dangling.Key != "fallback" || dangling.Token != "-bf" || dangling.Index != 3 {
+		t.Errorf("expected DanglingOptionError, found %v", err)
+	}
+
+	_, err = cli.ParseArgs(setuParseApp(), []string{"git", "checkout", "-fb", "x", "dev"})
+	var nonterminal *cli.NonTerminalFlagError
+	if !errors.As(err, &nonterminal) || nonterminal.Key != "f" || nonterminal.Index != 2 {
+		t.Errorf("expected NonTerminalFlagError, found %v", err)
+	}
+}
+
+func TestParseArgs_ParseErrorWrapped_ok(t *testing.T) {
+	_, err := cli.ParseArgs(setuParseApp(), []string{"git", "checkout", "--foo", "dev"})
+	var e *cli.UnknownOptionError
+	if !errors.As(fmt.Errorf("parsing: %w", err), &e) {
+		t.Errorf("expected UnknownOptionError to be found in the chain, found %v", err)
+	}
+}
+
+func TestParseArgs_ParseErrorLocation_ok(t *testing.T) {
+	appargs := []string{"git", "checkout", "--foo", "dev"}
+	_, err := cli.ParseArgs(setuParseApp(), appargs)
+	var loc cli.Located
+	if !errors.As(fmt.Errorf("parsing: %w", err), &loc) {
+		t.Fatalf("expected Located to be found in the chain, found %v", err)
+	}
+	if loc.Error() != err.Error() {
+		t.Errorf("expected message %q, found %q", err.Error(), loc.Error())
+	}
+	assertParseErrorLocation(t, appargs, loc.Location(), "git checkout", "--foo", 2)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// respArg is an argument read from a response file, along with its origin and the index of the
+// application argument it is expanded from.
+type respArg struct {
+	value string
+	file  string
+	line  int
+	index int
+}
+
+// expandResponseFiles replaces every `@path` argument with the arguments read from the file at path,
+// recursively. Relative paths in the application arguments are relative to dir, the working directory of
+// the process if empty, those in response files to the referencing file. A leading `@@` escapes a literal
+// `@`. Arguments following `--`, given directly or in a response file, are passed through verbatim.
+// Errors are returned as ResponseFileError located at the `@path` application argument.
+func expandResponseFiles(appargs []string, invocation []string, dir string) ([]string, error) {
+	args := make([]respArg, len(appargs))
+	for i, arg := range appargs {
+		args[i] = respArg{value: arg, index: i}
+	}
+	dashdash := false
+	res, err := expandRespArgs(args, dir, nil, &dashdash)
+	if rerr, ok := err.(*ResponseFileError); ok {
+		rerr.Invocation = invocation
+		rerr.Token = appargs[rerr.Index]
+		// the executable is at index 0
+		rerr.Index++
+	}
+	return res, err
+}
+
+func expandRespArgs(args []respArg, dir string, stack []string, dashdash *bool) ([]string, error) {
+	var res []string
+	for _, arg := range args {
+		if *dashdash || arg.value == "--" {
+			*dashdash = true
+			res = append(res, arg.value)
+			continue
+		}
+		if strings.HasPrefix(arg.value, "@@") {
+			res = append(res, arg.value[1:])
+			continue
+		}
+		if len(arg.value) < 2 || arg.value[0] != '@' {
+			res = append(res, arg.value)
+			continue
+		}
+
+		fname := arg.value[1:]
+		if arg.file != "" && !filepath.IsAbs(fname) {
+			fname = filepath.Join(filepath.Dir(arg.file), fname)
+		} else if dir != "" && !filepath.IsAbs(fname) {
+			fname = filepath.Join(dir, fname)
+		}
+		abs, fileargs, err := readResponseFile(fname, stack, arg.index)
+		if err != nil {
+			rerr, ok := err.(*ResponseFileError)
+			if !ok {
+				rerr = &ResponseFileError{File: fname, Err: err}
+			}
+			if arg.file != "" {
+				rerr = &ResponseFileError{File: arg.file, Line: arg.line, Err: rerr}
+			}
+			rerr.Index = arg.index
+			return nil, rerr
+		}
+		expanded, err := expandRespArgs(fileargs, dir, append(stack, abs), dashdash)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expanded...)
+	}
+	return res, nil
+}
+
+func readResponseFile(fname string, stack []string, index int) (abs string, res []respArg, err error) {
+	if abs, err = filepath.Abs(fname); err != nil {
+		return abs, nil, err
+	}
+	for _, visited := range stack {
+		if visited == abs {
+			return abs, nil, errorf(MsgCyclicReference)
+		}
+	}
+
+	f, err := os.Open(fname)
+	if err != nil {
+		if perr, ok := err.(*os.PathError); ok {
+			err = perr.Err
+		}
+		return abs, nil, err
+	}
+	defer f.Close()
+
+	// read lines of any length as response files are used to exceed the limits of the command line
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		text, rerr := r.ReadString('\n')
+		if rerr != nil && rerr != io.EOF {
+			return abs, nil, rerr
+		}
+		if rerr == io.EOF && text == "" {
+			break
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		values, err := splitShellQuoted(text)
+		if err != nil {
+			return abs, nil, &ResponseFileError{File: fname, Line: line, Err: err}
+		}
+		for _, value := range values {
+			res = append(res, respArg{value: value, file: fname, line: line, index: index})
+		}
+	}
+	return abs, res, nil
+}
+
+// splitShellQuoted splits a line into arguments at unquoted white space. Single quotes preserve
+// the quoted text literally, within double quotes and outside of quotes a backslash escapes the
+// next character.
+func splitShellQuoted(line string) ([]string, error) {
+	var res []string
+	var current bytes.Buffer
+	inarg := false
+	quote := rune(0)
+	escaped := false
+	for _, char := range line {
+		switch {
+		case escaped:
+			current.WriteRune(char)
+			escaped = false
+		case quote == '\'':
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\\':
+			escaped = true
+			inarg = true
+		case quote == '"':
+			if char == quote {
+				quote = 0
+			} else {
+				current.WriteRune(char)
+			}
+		case char == '\'' || char == '"':
+			quote = char
+			inarg = true
+		case char == ' ' || char == '\t':
+			if inarg {
+				res = append(res, current.String())
+				current.Reset()
+				inarg = false
+			}
+		default:
+			current.WriteRune(char)
+			inarg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errorf(MsgUnterminatedQuote, quote)
+	}
+	if escaped {
+		return nil, errorf(MsgTrailingBackslash)
+	}
+	if inarg {
+		res = append(res, current.String())
+	}
+	return res, nil
+}
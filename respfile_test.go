@@ -0,0 +1,136 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupRespFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestApp_Parse_ResponseFile_Ok(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{
+		"args.txt":   "# checkout options\n-f 'some master'\n\n\"d\\\"ev\" @" + "nested.txt\n",
+		"nested.txt": "--str=a\\ b\n",
+	})
+	defer os.RemoveAll(dir)
+
+	a := setuParseApp().WithResponseFiles(true)
+	invocation, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@" + filepath.Join(dir, "args.txt")})
+	assertAppParseOk(t, "[git checkout] [d\"ev] map[fallback:some master str:a b]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileEscaped_Ok(t *testing.T) {
+	a := setuParseApp().WithResponseFiles(true)
+	invocation, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@@dev"})
+	assertAppParseOk(t, "[git checkout] [@dev] map[]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileDisabled_Ok(t *testing.T) {
+	invocation, args, opts, err := cli.Parse(setuParseApp(), []string{"git", "checkout", "@dev"})
+	assertAppParseOk(t, "[git checkout] [@dev] map[]", invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileMissing_Error(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "dev\n@missing.txt\n"})
+	defer os.RemoveAll(dir)
+
+	a := setuParseApp().WithResponseFiles(true)
+	fname := filepath.Join(dir, "args.txt")
+	invocation, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@" + fname})
+	assertAppParseError(t, "[git] [] map[]", fname+":2: response file "+filepath.Join(dir, "missing.txt")+": no such file or directory",
+		invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileMalformed_Error(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "dev\n-f 'master\n"})
+	defer os.RemoveAll(dir)
+
+	a := setuParseApp().WithResponseFiles(true)
+	fname := filepath.Join(dir, "args.txt")
+	invocation, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@" + fname})
+	assertAppParseError(t, "[git] [] map[]", fname+":2: unterminated quote '", invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileCycle_Error(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{})
+	defer os.RemoveAll(dir)
+	first, second := filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt")
+	ioutil.WriteFile(first, []byte("@"+second), 0600)
+	ioutil.WriteFile(second, []byte("dev @"+first), 0600)
+
+	a := setuParseApp().WithResponseFiles(true)
+	invocation, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@" + first})
+	assertAppParseError(t, "[git] [] map[]", second+":1: response file "+first+": cyclic reference",
+		invocation, args, opts, err)
+}
+
+func TestApp_Parse_ResponseFileLongLine_Ok(t *testing.T) {
+	long := strings.Repeat("x", 100000)
+	dir := setupRespFiles(t, map[string]string{"args.txt": "-s " + long})
+	defer os.RemoveAll(dir)
+
+	a := setuParseApp().WithResponseFiles(true)
+	_, args, opts, err := cli.Parse(a, []string{"git", "checkout", "@" + filepath.Join(dir, "args.txt"), "dev"})
+	if err != nil || len(args) != 1 || opts["str"] != long {
+		t.Errorf("expected long option value, found %v, %v", args, err)
+	}
+}
+
+func TestApp_ParseArgs_ResponseFileAfterDashDash_Ok(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "a.sh -- @keep.txt\n"})
+	defer os.RemoveAll(dir)
+
+	a := setupPassthroughApp().WithResponseFiles(true)
+	res, err := cli.ParseArgs(a, []string{"git", "run", "@" + filepath.Join(dir, "args.txt"), "@@x", "@y"})
+	assertAppParseOk(t, "[git run] [a.sh] map[]", res.Invocation, res.Args, res.Opts, err)
+	assertAppParseOk(t, "[git run] [@keep.txt @@x @y] map[]", res.Invocation, res.Passthrough, res.Opts, err)
+
+	res, err = cli.ParseArgs(a, []string{"git", "run", "a.sh", "--", "@" + filepath.Join(dir, "args.txt")})
+	assertAppParseOk(t, "[git run] [@"+filepath.Join(dir, "args.txt")+"] map[]", res.Invocation, res.Passthrough, res.Opts, err)
+}
+
+func TestApp_ParseArgs_ResponseFileError_ok(t *testing.T) {
+	dir := setupRespFiles(t, map[string]string{"args.txt": "dev\n@missing.txt\n"})
+	defer os.RemoveAll(dir)
+
+	a := setuParseApp().WithResponseFiles(true)
+	fname := filepath.Join(dir, "args.txt")
+	appargs := []string{"git", "checkout", "-f", "@" + fname}
+	_, err := cli.ParseArgs(a, appargs)
+	var e *cli.ResponseFileError
+	if !errors.As(err, &e) || e.File != fname || e.Line != 2 {
+		t.Fatalf("expected ResponseFileError for %s:2, found %v", fname, err)
+	}
+	var nested *cli.ResponseFileError
+	if !errors.As(e.Err, &nested) || nested.File != filepath.Join(dir, "missing.txt") || nested.Line != 0 {
+		t.Errorf("expected nested ResponseFileError for missing.txt, found %v", e.Err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected error wrapping os.ErrNotExist, found %v", err)
+	}
+	var loc cli.Located
+	if !errors.As(err, &loc) {
+		t.Fatalf("expected Located, found %v", err)
+	}
+	assertParseErrorLocation(t, appargs, loc.Location(), "git", "@"+fname, 3)
+}
@@ -4,6 +4,8 @@
 package cli_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/teris-io/cli"
@@ -79,6 +81,100 @@ func TestApp_Run_NestedCommandWithOptionalArg_ok(t *testing.T) {
 
 func assertAppRunOk(t *testing.T, expectedCode, actualCode int) {
 	if expectedCode != actualCode {
-		t.Errorf("expected exit code: %s, found: %v", expectedCode, actualCode)
+		t.Errorf("expected exit code: %v, found: %v", expectedCode, actualCode)
 	}
 }
+
+func setupDeprecatedApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch or revision").
+		WithShortcut("co").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithArg(cli.NewArg("fallback", "branch to fallback").AsOptional().AsDeprecated("", "")).
+		WithOption(cli.NewOption("force", "Force checkout").WithChar('f').WithType(cli.TypeBool).AsDeprecated("", "--discard")).
+		WithOption(cli.NewOption("discard", "Discard local changes").WithType(cli.TypeBool)).
+		AsDeprecated("to be removed in v2", "switch").
+		WithAction(func(args []string, options map[string]string) int {
+			return 25
+		})
+
+	return cli.New("git tool").
+		WithCommand(co)
+}
+
+func TestApp_Run_DeprecatedCommand_WarnsAndRuns(t *testing.T) {
+	a := setupDeprecatedApp()
+	w := &stringwriter{}
+	code := a.Run([]string{"./foo", "co", "dev"}, w)
+	assertAppRunOk(t, 25, code)
+	assertAppUsageOk(t, "warning: command checkout is deprecated: to be removed in v2, use switch instead\n", w.str)
+}
+
+func TestApp_Run_DeprecatedOptionAndArg_WarnsToWarningWriter(t *testing.T) {
+	w := &stringwriter{}
+	ww := &stringwriter{}
+	a := setupDeprecatedApp().WithWarnings(ww)
+	code := a.Run([]string{"./foo", "co", "-f", "dev", "master"}, w)
+	assertAppRunOk(t, 25, code)
+	assertAppUsageOk(t, "", w.str)
+	expected := `warning: command checkout is deprecated: to be removed in v2, use switch instead
+warning: option --force is deprecated, use --discard instead
+warning: argument fallback is deprecated
+`
+	assertAppUsageOk(t, expected, ww.str)
+}
+
+func TestApp_Run_StrictDeprecation_Fails(t *testing.T) {
+	a := setupDeprecatedApp().WithStrictDeprecation(true)
+	w := &stringwriter{}
+	code := a.Run([]string{"./foo", "co", "dev"}, w)
+	assertAppRunOk(t, 1, code)
+	expected := `fatal: command checkout is deprecated: to be removed in v2, use switch instead
+usage: foo checkout [--force] [--discard] <branch> [fallback]
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Run_PassthroughSeparatedForAction(t *testing.T) {
+	var actual string
+	run := cli.NewCommand("run", "Run a script").
+		WithArg(cli.NewArg("script", "script to run")).
+		WithPassthrough(cli.NewArg("extra", "script arguments")).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			actual = fmt.Sprintf("%v %v", args, cli.PassthroughFrom(ctx))
+			return len(cli.PassthroughFrom(ctx))
+		})
+	a := cli.New("tool").WithCommand(run)
+
+	w := &stringwriter{}
+	code := a.Run([]string{"./tool", "run", "a", "--", "b", "--", "c"}, w)
+	assertAppRunOk(t, 3, code)
+	assertAppUsageOk(t, "[a] [b -- c]", actual)
+
+	code = a.Run([]string{"./tool", "run", "a", "b"}, w)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: unknown arguments [b]\nusage: tool run <script> [-- <extra>...]\n", w.str)
+}
+
+func TestApp_Run_NonInterspersedPassthroughKeepsLiteralDashDash(t *testing.T) {
+	var actual string
+	exec := cli.NewCommand("exec", "Execute a command").
+		WithInterspersed(false).
+		WithArg(cli.NewArg("cmd", "command to execute")).
+		WithArg(cli.NewArg("args", "command arguments").AsOptional()).
+		WithPassthrough(cli.NewArg("extra", "extra arguments")).
+		WithContextAction(func(ctx context.Context, args []string, options map[string]string) int {
+			actual = fmt.Sprintf("%v %v", args, cli.PassthroughFrom(ctx))
+			return 0
+		})
+	a := cli.New("tool").WithCommand(exec)
+
+	code := a.Run([]string{"./tool", "exec", "grep", "--", "-v"}, &stringwriter{})
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "[grep -- -v] []", actual)
+
+	code = a.Run([]string{"./tool", "exec", "--", "grep", "--", "-v"}, &stringwriter{})
+	assertAppRunOk(t, 1, code)
+	code = a.Run([]string{"./tool", "exec", "grep", "x"}, &stringwriter{})
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "[grep x] []", actual)
+}
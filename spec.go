@@ -0,0 +1,199 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SpecVersion is the version of the JSON schema of the application specification. It is incremented
+// on incompatible changes only, new optional fields can be added within a version.
+const SpecVersion = 1
+
+const specFlag = "--cli-spec"
+
+// Spec defines the machine-readable specification of an application, serializable to JSON.
+type Spec struct {
+	Version         int           `json:"version"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description"`
+	Args            []ArgSpec     `json:"args,omitempty"`
+	Passthrough     *ArgSpec      `json:"passthrough,omitempty"`
+	NonInterspersed bool          `json:"nonInterspersed,omitempty"`
+	Options         []OptionSpec  `json:"options,omitempty"`
+	Commands        []CommandSpec `json:"commands,omitempty"`
+	Examples        []ExampleSpec `json:"examples,omitempty"`
+	Action          string        `json:"action,omitempty"`
+}
+
+// CommandSpec defines the specification of a command.
+type CommandSpec struct {
+	Key             string           `json:"key"`
+	Shortcut        string           `json:"shortcut,omitempty"`
+	Description     string           `json:"description"`
+	Args            []ArgSpec        `json:"args,omitempty"`
+	Passthrough     *ArgSpec         `json:"passthrough,omitempty"`
+	NonInterspersed bool             `json:"nonInterspersed,omitempty"`
+	Options         []OptionSpec     `json:"options,omitempty"`
+	Commands        []CommandSpec    `json:"commands,omitempty"`
+	Category        string           `json:"category,omitempty"`
+	Hidden          bool             `json:"hidden,omitempty"`
+	Deprecated      *DeprecationSpec `json:"deprecated,omitempty"`
+	Examples        []ExampleSpec    `json:"examples,omitempty"`
+	Action          string           `json:"action,omitempty"`
+}
+
+// ArgSpec defines the specification of a positional argument.
+type ArgSpec struct {
+	Key         string           `json:"key"`
+	Description string           `json:"description"`
+	Type        string           `json:"type"`
+	Optional    bool             `json:"optional,omitempty"`
+	Deprecated  *DeprecationSpec `json:"deprecated,omitempty"`
+}
+
+// OptionSpec defines the specification of an option.
+type OptionSpec struct {
+	Key         string           `json:"key"`
+	Char        string           `json:"char,omitempty"`
+	Description string           `json:"description"`
+	Type        string           `json:"type"`
+	Group       string           `json:"group,omitempty"`
+	Local       bool             `json:"local,omitempty"`
+	Hidden      bool             `json:"hidden,omitempty"`
+	Deprecated  *DeprecationSpec `json:"deprecated,omitempty"`
+}
+
+// ExampleSpec defines the specification of a usage example.
+type ExampleSpec struct {
+	Cmdline     string `json:"cmdline"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// DeprecationSpec defines the deprecation of a command, argument or option.
+type DeprecationSpec struct {
+	Notice      string `json:"notice,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// ExportSpec exports the complete definition of the application named appname, including hidden
+// commands and options, into its specification. Built-in commands such as `completion` are excluded.
+func ExportSpec(a App, appname string) Spec {
+	return Spec{
+		Version:         SpecVersion,
+		Name:            appname,
+		Description:     a.Description(),
+		Args:            argSpecs(a.Args()),
+		Passthrough:     passthroughSpec(a.Passthrough()),
+		NonInterspersed: !a.Interspersed(),
+		Options:         optionSpecs(a.Options()),
+		Commands:        commandSpecs(a.Commands()),
+		Examples:        exampleSpecs(a.Examples()),
+		Action:          actionName(a),
+	}
+}
+
+// WriteSpec outputs the specification of the application named appname as indented JSON.
+func WriteSpec(a App, appname string, w io.Writer) error {
+	data, err := json.MarshalIndent(ExportSpec(a, appname), "", "  ")
+	if err == nil {
+		_, err = w.Write(append(data, '\n'))
+	}
+	return err
+}
+
+func commandSpecs(cmds []Command) []CommandSpec {
+	var res []CommandSpec
+	for _, cmd := range cmds {
+		if c, ok := cmd.(*command); ok && c.builtin != nil {
+			continue
+		}
+		res = append(res, CommandSpec{
+			Key:             cmd.Key(),
+			Shortcut:        cmd.Shortcut(),
+			Description:     cmd.Description(),
+			Args:            argSpecs(cmd.Args()),
+			Passthrough:     passthroughSpec(cmd.Passthrough()),
+			NonInterspersed: !cmd.Interspersed(),
+			Options:         optionSpecs(cmd.Options()),
+			Commands:        commandSpecs(cmd.Commands()),
+			Category:        cmd.Category(),
+			Hidden:          cmd.Hidden(),
+			Deprecated:      deprecationSpec(cmd),
+			Examples:        exampleSpecs(cmd.Examples()),
+			Action:          actionName(cmd),
+		})
+	}
+	return res
+}
+
+func argSpecs(args []Arg) []ArgSpec {
+	var res []ArgSpec
+	for _, arg := range args {
+		res = append(res, ArgSpec{
+			Key:         arg.Key(),
+			Description: arg.Description(),
+			Type:        typename(arg.Type()),
+			Optional:    arg.Optional(),
+			Deprecated:  deprecationSpec(arg),
+		})
+	}
+	return res
+}
+
+func passthroughSpec(arg Arg) *ArgSpec {
+	if arg == nil {
+		return nil
+	}
+	return &argSpecs([]Arg{arg})[0]
+}
+
+func optionSpecs(opts []Option) []OptionSpec {
+	var res []OptionSpec
+	for _, opt := range opts {
+		char := ""
+		if opt.CharKey() != rune(0) {
+			char = string(opt.CharKey())
+		}
+		res = append(res, OptionSpec{
+			Key:         opt.Key(),
+			Char:        char,
+			Description: opt.Description(),
+			Type:        typename(opt.Type()),
+			Group:       opt.Group(),
+			Local:       opt.Local(),
+			Hidden:      opt.Hidden(),
+			Deprecated:  deprecationSpec(opt),
+		})
+	}
+	return res
+}
+
+func exampleSpecs(examples []Example) []ExampleSpec {
+	var res []ExampleSpec
+	for _, example := range examples {
+		res = append(res, ExampleSpec{Cmdline: example.Cmdline, Explanation: example.Explanation})
+	}
+	return res
+}
+
+func deprecationSpec(d deprecatable) *DeprecationSpec {
+	if !d.Deprecated() {
+		return nil
+	}
+	notice, replacement := d.Deprecation()
+	return &DeprecationSpec{Notice: notice, Replacement: replacement}
+}
+
+// actionName returns the name of the action bound when loading the specification, if any.
+func actionName(x interface{}) string {
+	switch x := x.(type) {
+	case *app:
+		return x.actionName
+	case *command:
+		return x.actionName
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupSpecApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithShortcut("co").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithArg(cli.NewArg("depth", "history depth").WithType(cli.TypeInt).AsOptional()).
+		WithOption(cli.NewOption("force", "Force").WithChar('f').WithType(cli.TypeBool).AsDeprecated("", "--discard")).
+		AsHidden()
+
+	exec := cli.NewCommand("exec", "Execute a command").
+		WithInterspersed(false).
+		WithCategory("Plumbing").
+		WithExample("git exec ls -la", "List files").
+		WithPassthrough(cli.NewArg("extra", "extra arguments"))
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithCommand(exec).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool).WithGroup("Output").AsLocal()).
+		WithCompletionCommand().
+		WithSpecFlag()
+}
+
+const specJSON = `{
+  "version": 1,
+  "name": "git",
+  "description": "git tool",
+  "options": [
+    {
+      "key": "verbose",
+      "char": "v",
+      "description": "Verbose execution",
+      "type": "bool",
+      "group": "Output",
+      "local": true
+    }
+  ],
+  "commands": [
+    {
+      "key": "checkout",
+      "shortcut": "co",
+      "description": "Check out a branch",
+      "args": [
+        {
+          "key": "branch",
+          "description": "branch to checkout",
+          "type": "string"
+        },
+        {
+          "key": "depth",
+          "description": "history depth",
+          "type": "int",
+          "optional": true
+        }
+      ],
+      "options": [
+        {
+          "key": "force",
+          "char": "f",
+          "description": "Force",
+          "type": "bool",
+          "deprecated": {
+            "replacement": "--discard"
+          }
+        }
+      ],
+      "hidden": true
+    },
+    {
+      "key": "exec",
+      "description": "Execute a command",
+      "passthrough": {
+        "key": "extra",
+        "description": "extra arguments",
+        "type": "string"
+      },
+      "nonInterspersed": true,
+      "category": "Plumbing",
+      "examples": [
+        {
+          "cmdline": "git exec ls -la",
+          "explanation": "List files"
+        }
+      ]
+    }
+  ]
+}
+`
+
+func TestWriteSpec_ok(t *testing.T) {
+	w := &stringwriter{}
+	if err := cli.WriteSpec(setupSpecApp(), "git", w); err != nil {
+		t.Fatal(err)
+	}
+	assertAppUsageOk(t, specJSON, w.str)
+}
+
+func TestApp_Run_SpecFlag_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupSpecApp().Run([]string{"/usr/bin/git", "--cli-spec"}, w)
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, specJSON, w.str)
+}
+
+func TestApp_Run_SpecFlagNotEnabled_error(t *testing.T) {
+	w := &stringwriter{}
+	code := cli.New("git tool").Run([]string{"/usr/bin/git", "--cli-spec"}, w)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: unknown option --cli-spec\nusage: git\n", w.str)
+}
+
+func TestLoadSpec_RoundTrip_ok(t *testing.T) {
+	spec := strings.Replace(specJSON, `          "explanation": "List files"
+        }
+      ]
+`, `          "explanation": "List files"
+        }
+      ],
+      "action": "exec"
+`, 1)
+	actions := map[string]cli.Action{
+		"exec": func(args []string, options map[string]string) int {
+			return 3 + len(args)
+		},
+	}
+	a, err := cli.LoadSpec(strings.NewReader(spec), actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &stringwriter{}
+	if err = cli.WriteSpec(a, "git", w); err != nil {
+		t.Fatal(err)
+	}
+	assertAppUsageOk(t, spec, w.str)
+
+	code := a.Run([]string{"git", "exec", "--", "ls", "-la"}, w)
+	assertAppRunOk(t, 3, code)
+}
+
+func TestLoadSpec_Invalid_error(t *testing.T) {
+	tests := map[string]string{
+		`{"version": 2}`:                                 "spec: unsupported version 2, expected 1",
+		`{"version": 1, "foo": 1}`:                       `spec: json: unknown field "foo"`,
+		`{"version": 1, "name": "git", "action": "foo"}`: "spec: git: unknown action foo",
+		`{"version": 1, "name": "git", "args": [{"key": "a", "optional": true}, {"key": "b"}]}`:    "spec: git: required argument b follows an optional one",
+		`{"version": 1, "name": "git", "options": [{"key": "a", "type": "float"}]}`:                `spec: git: unknown type "float"`,
+		`{"version": 1, "name": "git", "commands": [{"key": "a", "shortcut": "b"}, {"key": "b"}]}`: "spec: git: duplicate command b",
+		`{"version": 1, "name": "git", "options": [{"key": "v", "char": "v"}], "commands": [{"key": "a",
+			"options": [{"key": "verbose", "char": "v"}]}]}`: "spec: git a: duplicate char key -v for option --verbose",
+	}
+	for spec, expected := range tests {
+		_, err := cli.LoadSpec(strings.NewReader(spec), nil)
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error %v, found %v", expected, err)
+		}
+	}
+}
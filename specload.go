@@ -0,0 +1,193 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoadSpec reads the JSON specification of an application, see Spec, and builds the application from it
+// binding actions by their names in the specification to the given registry, see FromSpec.
+func LoadSpec(r io.Reader, actions map[string]Action) (App, error) {
+	var spec Spec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("spec: %v", err)
+	}
+	return FromSpec(spec, actions)
+}
+
+// FromSpec validates the specification of an application and builds the application from it binding
+// actions by their names in the specification to the given registry. The specification is validated for
+// the supported version, value types, duplicate commands and options, required arguments following optional
+// ones and unknown actions. Exporting the application with ExportSpec yields the original specification.
+func FromSpec(spec Spec, actions map[string]Action) (App, error) {
+	if spec.Version != SpecVersion {
+		return nil, fmt.Errorf("spec: unsupported version %d, expected %d", spec.Version, SpecVersion)
+	}
+	l := &loader{actions: actions}
+	a := &app{descr: spec.Description, nointer: spec.NonInterspersed}
+	a.args = l.args(spec.Args, spec.Name)
+	a.passthru = l.passthrough(spec.Passthrough, spec.Name)
+	a.opts = l.opts(spec.Options, nil, spec.Name)
+	a.action, a.actionName = l.action(spec.Action, spec.Name)
+	a.cmds = l.cmds(spec.Commands, inherit(nil, a.opts), spec.Name)
+	a.examples = examples(spec.Examples)
+	if l.err != nil {
+		return nil, l.err
+	}
+	return a, nil
+}
+
+// loader builds the application from its specification recording the first validation error.
+type loader struct {
+	actions map[string]Action
+	err     error
+}
+
+func (l *loader) fail(where, format string, args ...interface{}) {
+	if l.err == nil {
+		l.err = fmt.Errorf("spec: %s: %s", where, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *loader) cmds(specs []CommandSpec, global []Option, where string) []Command {
+	var res []Command
+	keys := make(map[string]bool)
+	for _, spec := range specs {
+		path := where + " " + spec.Key
+		if spec.Key == "" {
+			l.fail(where, "command with no key")
+		}
+		for _, key := range []string{spec.Key, spec.Shortcut} {
+			if key != "" && keys[key] {
+				l.fail(where, "duplicate command %s", key)
+			}
+			keys[key] = true
+		}
+
+		c := &command{key: spec.Key, descr: spec.Description, shortcut: spec.Shortcut, hidden: spec.Hidden,
+			nointer: spec.NonInterspersed, category: spec.Category}
+		c.args = l.args(spec.Args, path)
+		c.passthru = l.passthrough(spec.Passthrough, path)
+		c.opts = l.opts(spec.Options, global, path)
+		c.action, c.actionName = l.action(spec.Action, path)
+		if spec.Deprecated != nil {
+			c.AsDeprecated(spec.Deprecated.Notice, spec.Deprecated.Replacement)
+		}
+		c.cmds = l.cmds(spec.Commands, inherit(global, c.opts), path)
+		c.examples = examples(spec.Examples)
+		res = append(res, c)
+	}
+	return res
+}
+
+func (l *loader) args(specs []ArgSpec, where string) []Arg {
+	var res []Arg
+	optional := false
+	for _, spec := range specs {
+		arg := l.arg(spec, where)
+		if optional && !arg.Optional() {
+			l.fail(where, "required argument %s follows an optional one", spec.Key)
+		}
+		optional = arg.Optional()
+		res = append(res, arg)
+	}
+	return res
+}
+
+func (l *loader) arg(spec ArgSpec, where string) Arg {
+	if spec.Key == "" {
+		l.fail(where, "argument with no key")
+	}
+	arg := NewArg(spec.Key, spec.Description).WithType(l.valueType(spec.Type, where))
+	if spec.Optional {
+		arg = arg.AsOptional()
+	}
+	if spec.Deprecated != nil {
+		arg = arg.AsDeprecated(spec.Deprecated.Notice, spec.Deprecated.Replacement)
+	}
+	return arg
+}
+
+func (l *loader) passthrough(spec *ArgSpec, where string) Arg {
+	if spec == nil {
+		return nil
+	}
+	return l.arg(*spec, where)
+}
+
+func (l *loader) opts(specs []OptionSpec, global []Option, where string) []Option {
+	var res []Option
+	for _, spec := range specs {
+		if spec.Key == "" || strings.HasPrefix(spec.Key, "-") {
+			l.fail(where, "invalid option key %q", spec.Key)
+		}
+		if utf8.RuneCountInString(spec.Char) > 1 {
+			l.fail(where, "invalid char key %q for option --%s", spec.Char, spec.Key)
+		}
+		opt := NewOption(spec.Key, spec.Description).WithType(l.valueType(spec.Type, where))
+		if spec.Char != "" {
+			opt = opt.WithChar([]rune(spec.Char)[0])
+		}
+		for _, other := range append(append([]Option{}, global...), res...) {
+			if other.Key() == opt.Key() {
+				l.fail(where, "duplicate option --%s", opt.Key())
+			} else if opt.CharKey() != rune(0) && other.CharKey() == opt.CharKey() {
+				l.fail(where, "duplicate char key -%s for option --%s", string(opt.CharKey()), opt.Key())
+			}
+		}
+		if spec.Group != "" {
+			opt = opt.WithGroup(spec.Group)
+		}
+		if spec.Local {
+			opt = opt.AsLocal()
+		}
+		if spec.Hidden {
+			opt = opt.AsHidden()
+		}
+		if spec.Deprecated != nil {
+			opt = opt.AsDeprecated(spec.Deprecated.Notice, spec.Deprecated.Replacement)
+		}
+		res = append(res, opt)
+	}
+	return res
+}
+
+func examples(specs []ExampleSpec) []Example {
+	var res []Example
+	for _, spec := range specs {
+		res = append(res, Example{Cmdline: spec.Cmdline, Explanation: spec.Explanation})
+	}
+	return res
+}
+
+func (l *loader) valueType(name, where string) ValueType {
+	if name == "" {
+		return TypeString
+	}
+	for _, tp := range []ValueType{TypeString, TypeBool, TypeInt, TypeNumber} {
+		if name == typename(tp) {
+			return tp
+		}
+	}
+	l.fail(where, "unknown type %q", name)
+	return TypeString
+}
+
+func (l *loader) action(name, where string) (Action, string) {
+	if name == "" {
+		return nil, ""
+	}
+	action, ok := l.actions[name]
+	if !ok {
+		l.fail(where, "unknown action %s", name)
+	}
+	return action, name
+}
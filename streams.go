@@ -0,0 +1,31 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Streams defines the standard streams of an application, see App.RunStreams and Env.
+type Streams struct {
+	// In is the standard input.
+	In io.Reader
+	// Out is the standard output receiving the usage, help and version output.
+	Out io.Writer
+	// Err is the standard error receiving errors, the short usage on errors and warnings.
+	Err io.Writer
+}
+
+// StdStreams returns the standard streams of the process: os.Stdin, os.Stdout and os.Stderr.
+func StdStreams() Streams {
+	return Streams{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}
+}
+
+// StreamsFrom returns the streams the application is run with for use in actions, hooks and middleware,
+// the standard streams of the process if the context does not originate from Run, see EnvFrom.
+func StreamsFrom(ctx context.Context) Streams {
+	return EnvFrom(ctx).Streams
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupStreamsApp() cli.App {
+	cat := cli.NewCommand("cat", "Copy input to output").
+		WithOption(cli.NewOption("fail", "Fail").WithType(cli.TypeBool)).
+		WithErrorAction(func(ctx context.Context, args []string, options map[string]string) error {
+			s := cli.StreamsFrom(ctx)
+			data, err := ioutil.ReadAll(s.In)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(s.Out, string(data))
+			if _, ok := options["fail"]; ok {
+				return fmt.Errorf("failed after %d bytes", len(data))
+			}
+			return nil
+		})
+
+	return cli.New("tool").
+		WithCommand(cat).
+		WithCommand(cli.NewCommand("old", "Old command").AsDeprecated("", "cat").
+			WithAction(func(args []string, options map[string]string) int {
+				return 0
+			})).
+		WithHelpCommand()
+}
+
+func runStreams(a cli.App, appargs []string, input string) (code int, out, errout string) {
+	o, e := &stringwriter{}, &stringwriter{}
+	code = a.RunStreams(context.Background(), appargs, cli.Streams{In: strings.NewReader(input), Out: o, Err: e})
+	return code, o.str, e.str
+}
+
+func TestApp_RunStreams_ok(t *testing.T) {
+	tests := []struct {
+		appargs []string
+		code    int
+		out     string
+		errout  string
+	}{
+		{[]string{"./tool", "cat"}, 0, "hello", ""},
+		{[]string{"./tool", "cat", "--fail"}, 1, "hello", "fatal: failed after 5 bytes\n"},
+		{[]string{"./tool", "cat", "--unknown"}, 1, "", "fatal: unknown option --unknown\nusage: tool cat [--fail]\n"},
+		{[]string{"./tool", "old"}, 0, "", "warning: command old is deprecated, use cat instead\n"},
+		{[]string{"./tool", "help", "unknown"}, 1, "", "fatal: unknown command or help topic unknown\nusage: tool help [command]\n"},
+	}
+	for _, test := range tests {
+		code, out, errout := runStreams(setupStreamsApp(), test.appargs, "hello")
+		assertAppRunOk(t, test.code, code)
+		assertAppUsageOk(t, test.out, out)
+		assertAppUsageOk(t, test.errout, errout)
+	}
+}
+
+func TestApp_RunStreams_HelpToOut_ok(t *testing.T) {
+	for _, appargs := range [][]string{{"./tool", "--help"}, {"./tool", "help", "cat"}} {
+		code, out, errout := runStreams(setupStreamsApp(), appargs, "")
+		assertAppRunOk(t, 0, code)
+		if !strings.HasPrefix(out, "tool") || errout != "" {
+			t.Errorf("expected usage on out only, found out: %v, err: %v", out, errout)
+		}
+	}
+}
+
+func TestStreamsFrom_DefaultsToStdStreams_ok(t *testing.T) {
+	s := cli.StreamsFrom(context.Background())
+	if s.In != os.Stdin || s.Out != os.Stdout || s.Err != os.Stderr {
+		t.Errorf("expected standard streams, found %v", s)
+	}
+}
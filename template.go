@@ -0,0 +1,65 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+// UsageTemplateFuncs are the functions available to usage templates in addition to the built-in ones:
+//
+//	join        joins a list of strings with a separator: {{join .Invocation " "}}
+//	wrap        wraps text to the given width returning the lines: {{range wrap .Description 60}}
+//	indent      indents all lines of the text by the given number of spaces: {{indent 4 .Description}}
+//	pad         pads the text with spaces to the given display width: {{pad .Key 20}}
+//	deprecation returns the deprecation annotation of an argument, option or command, if deprecated, in
+//	            the language of the model
+//
+// Localised headings are available with the Message method of the model: {{.Message "options"}}.
+var UsageTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"wrap": wrap,
+	"indent": func(n int, text string) string {
+		prefix := strings.Repeat(" ", n)
+		return prefix + strings.Replace(text, "\n", "\n"+prefix, -1)
+	},
+	"pad": func(text string, width int) string {
+		if w := strwidth(text); w < width {
+			return text + strings.Repeat(" ", width-w)
+		}
+		return text
+	},
+	"deprecation": func(d deprecatable) string {
+		return deprecationnote(English, d)
+	},
+}
+
+type templateRenderer struct {
+	tmpl *template.Template
+}
+
+// NewTemplateRenderer creates a UsageRenderer executing the text/template given by text with the
+// UsageModel of the command as data, see UsageTemplateFuncs for the available functions.
+func NewTemplateRenderer(text string) (UsageRenderer, error) {
+	tmpl, err := template.New("usage").Funcs(UsageTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &templateRenderer{tmpl: tmpl}, nil
+}
+
+func (r *templateRenderer) RenderUsage(m UsageModel, w io.Writer) error {
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"deprecation": func(d deprecatable) string {
+			return deprecationnote(m.Catalog, d)
+		},
+	})
+	return tmpl.Execute(w, m)
+}
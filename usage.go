@@ -4,9 +4,9 @@
 package cli
 
 import (
-	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 )
 
@@ -16,172 +16,338 @@ type usageline struct {
 	value   string
 }
 
-// Usage prints out the complete usage string.
+// Usage prints out the complete usage string excluding hidden commands and options. The usage width and
+// language are looked up in the environment of the process, see UsageEnv.
 func Usage(a App, invocation []string, w io.Writer) error {
+	return usage(a, invocation, w, false, os.LookupEnv)
+}
+
+// UsageAll prints out the complete usage string including hidden commands and options. The usage width
+// and language are looked up in the environment of the process, see UsageAllEnv.
+func UsageAll(a App, invocation []string, w io.Writer) error {
+	return usage(a, invocation, w, true, os.LookupEnv)
+}
+
+// UsageEnv prints out the usage same as Usage, but to the Out stream of the given environment looking
+// up the usage width and language in the latter, e.g. the one obtained with EnvFrom in an action.
+func UsageEnv(a App, invocation []string, env Env) error {
+	env = env.withDefaults()
+	return usage(a, invocation, env.Out, false, env.LookupEnv)
+}
+
+// UsageAllEnv prints out the usage same as UsageAll in the given environment, see UsageEnv.
+func UsageAllEnv(a App, invocation []string, env Env) error {
+	env = env.withDefaults()
+	return usage(a, invocation, env.Out, true, env.LookupEnv)
+}
+
+// UsageModel captures the resolved definitions of the command (or application) the usage is rendered for.
+// Hidden commands and options are included only if requested with `--help-all`, see UsageAll.
+type UsageModel struct {
+	// Invocation is the command invocation path: application -> first level command -> second level
+	// command etc.
+	Invocation []string
+	// Description is the description of the command being invoked.
+	Description string
+	// Args lists the positional arguments of the command.
+	Args []Arg
+	// Passthrough defines the arguments following `--`, nil if not declared.
+	Passthrough Arg
+	// Options lists the options of the command itself.
+	Options []Option
+	// GlobalOptions lists the options inherited from the ancestors of the command.
+	GlobalOptions []Option
+	// Commands lists the sub-commands of the command.
+	Commands []Command
+	// HelpTopics lists the help topics, set for the top-level application with the built-in help command
+	// only, see App.WithHelpCommand.
+	HelpTopics []HelpTopic
+	// Examples lists the usage examples of the command.
+	Examples []Example
+	// Width is the width in columns to wrap the usage to, 0 if the usage is not to be wrapped.
+	Width int
+	// Catalog is the catalog of messages in the language of the usage, see App.WithCatalog.
+	Catalog Catalog
+}
+
+// Message returns the message with the given identifier from the catalog of the model formatted with
+// args, falling back to English.
+func (m UsageModel) Message(id MessageID, args ...interface{}) string {
+	return msg(m.Catalog, id, args...)
+}
+
+// Synopsis returns the single line synopsis of the command, e.g. `git checkout [--verbose] <revision>`.
+func (m UsageModel) Synopsis() string {
+	return strings.Join(m.Invocation, " ") + optstring(synopsisOpts(m.GlobalOptions, m.Options)) + argstring(m.Args, m.Passthrough)
+}
+
+// UsageRenderer renders the usage of a command given its model, see App.WithUsageRenderer.
+type UsageRenderer interface {
+	// RenderUsage writes the usage for the model to w.
+	RenderUsage(m UsageModel, w io.Writer) error
+}
+
+// DefaultUsageRenderer renders the usage in the default layout listing the synopsis followed by the
+// description, arguments, options, global options, sub-commands, help topics and examples sections.
+var DefaultUsageRenderer UsageRenderer = defaultRenderer{}
+
+// usage outputs the usage looking up the COLUMNS environment variable with lookup.
+func usage(a App, invocation []string, w io.Writer, all bool, lookup func(string) (string, bool)) error {
 	if len(invocation) < 1 {
-		return errors.New("invalid invocation path []")
-	}
-
-	descr := a.Description()
-	cmds := a.Commands()
-	args := a.Args()
-	opts := a.Options()
-
-	if len(invocation) > 1 {
-		for _, key := range invocation[1:] {
-			matched := false
-			for _, cmd := range cmds {
-				if cmd.Key() == key {
-					descr = cmd.Description()
-					cmds = cmd.Commands()
-					args = cmd.Args()
-					opts = append(opts, cmd.Options()...)
-					matched = true
-					break
-				}
-			}
-			// should never happen if invocation originates from the parser
-			if !matched {
-				// ignore errors here as no alternative writer is available
-				err := fmt.Errorf("fatal: invalid invocation path %v\n", invocation)
-				fmt.Fprint(w, err.Error())
-				return err
-			}
-		}
+		return errorf(MsgInvalidInvocation, invocation)
+	}
+
+	sc, err := resolve(a, invocation)
+	// should never happen if invocation originates from the parser
+	if err != nil {
+		// ignore errors here as no alternative writer is available
+		fmt.Fprintln(w, msg(catalog(a, lookup), MsgFatal, err))
+		return err
+	}
+
+	m := UsageModel{
+		Invocation:    invocation,
+		Description:   sc.descr,
+		Args:          sc.args,
+		Passthrough:   sc.passthrough,
+		Options:       sc.opts,
+		GlobalOptions: sc.global,
+		Commands:      sc.cmds,
+		Examples:      sc.examples,
+		Width:         usageWidth(a, lookup, w),
+		Catalog:       catalog(a, lookup),
 	}
+	if !all {
+		m.Options = visibleOpts(m.Options)
+		m.GlobalOptions = visibleOpts(m.GlobalOptions)
+		m.Commands = visibleCmds(m.Commands)
+	}
+	if len(invocation) == 1 && hasHelpCommand(a) {
+		m.HelpTopics = a.HelpTopics()
+	}
+
+	r := a.UsageRenderer()
+	if r == nil {
+		r = DefaultUsageRenderer
+	}
+	return r.RenderUsage(m, w)
+}
+
+type defaultRenderer struct{}
+
+func (defaultRenderer) RenderUsage(m UsageModel, w io.Writer) error {
+	cmds := m.Commands
+	args := m.Args
+	opts := m.Options
+	global := m.GlobalOptions
 
 	indent := "    "
-	thiscmd := strings.Join(invocation, " ")
-	fmt.Fprintf(w, "%s%s%s\n\n", thiscmd, optstring(opts), argstring(args))
-	fmt.Fprintln(w, "Description:")
-	fmt.Fprintf(w, "%s%s\n", indent, descr)
+	width := m.Width
+	thiscmd := strings.Join(m.Invocation, " ")
+	items := append(optitems(synopsisOpts(global, opts)), argitems(args, m.Passthrough)...)
+	fmt.Fprintf(w, "%s\n\n", synopsis(thiscmd, items, width, indent))
+	fmt.Fprintf(w, "%s:\n", m.Message(MsgDescription))
+	for _, line := range wrap(m.Description, width-len(indent)) {
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
 
 	var lines []usageline
 	maxkey := 0
+	if m.Passthrough != nil {
+		args = append(append([]Arg{}, args...), m.Passthrough)
+	}
 	if len(args) > 0 {
-		for _, arg := range args {
+		for i, arg := range args {
 			value := arg.Description()
-			if arg.Optional() {
-				value += ", optional"
+			if m.Passthrough != nil && i == len(args)-1 {
+				value += m.Message(MsgFollowingDashDash)
+			} else if arg.Optional() {
+				value += m.Message(MsgOptional)
 			}
+			value += deprecationnote(m.Catalog, arg)
 			line := usageline{
-				section: "Arguments",
+				section: m.Message(MsgArguments),
 				key:     arg.Key(),
 				value:   value,
 			}
 			lines = append(lines, line)
-			if len(line.key) > maxkey {
-				maxkey = len(line.key)
+			if kw := strwidth(line.key); kw > maxkey {
+				maxkey = kw
 			}
 		}
 	}
 
-	if len(opts) > 0 {
-		for _, opt := range opts {
+	for _, section := range optsections(opts, global, m.Message(MsgOptions), m.Message(MsgGlobalOptions)) {
+		for _, opt := range section.opts {
 			charstr := "    "
 			if opt.CharKey() != rune(0) {
 				charstr = "-" + string(opt.CharKey()) + ", "
 			}
 
 			line := usageline{
-				section: "Options",
+				section: section.name,
 				key:     charstr + "--" + opt.Key(),
-				value:   opt.Description(),
+				value:   opt.Description() + deprecationnote(m.Catalog, opt),
 			}
 			lines = append(lines, line)
-			if len(line.key) > maxkey {
-				maxkey = len(line.key)
+			if kw := strwidth(line.key); kw > maxkey {
+				maxkey = kw
 			}
 		}
 	}
 
-	if len(cmds) > 0 {
-		for _, cmd := range cmds {
+	for _, section := range cmdsections(cmds, m.Message(MsgSubCommands)) {
+		for _, cmd := range section.cmds {
 			shortstr := ""
 			if cmd.Shortcut() != "" {
-				shortstr = ", shortcut: " + cmd.Shortcut()
+				shortstr = m.Message(MsgShortcut, cmd.Shortcut())
 			}
 
 			line := usageline{
-				section: "Sub-commands",
+				section: section.name,
 				key:     thiscmd + " " + cmd.Key(),
-				value:   cmd.Description() + shortstr,
+				value:   cmd.Description() + shortstr + deprecationnote(m.Catalog, cmd),
 			}
 			lines = append(lines, line)
-			if len(line.key) > maxkey {
-				maxkey = len(line.key)
+			if kw := strwidth(line.key); kw > maxkey {
+				maxkey = kw
 			}
 		}
 	}
 
+	for _, topic := range m.HelpTopics {
+		line := usageline{
+			section: m.Message(MsgHelpTopics),
+			key:     thiscmd + " " + helpKey + " " + topic.Key,
+			value:   topic.Description,
+		}
+		lines = append(lines, line)
+		if kw := strwidth(line.key); kw > maxkey {
+			maxkey = kw
+		}
+	}
+
+	keycol := len(indent) + maxkey + 3
 	lastsection := ""
 	for _, line := range lines {
 		if line.section != lastsection {
 			fmt.Fprintf(w, "\n%s:\n", line.section)
 		}
 		lastsection = line.section
-		spacer := 3 + maxkey - len(line.key)
-		fmt.Fprintf(w, "%s%s%s%s\n", indent, line.key, strings.Repeat(" ", spacer), line.value)
+		spacer := 3 + maxkey - strwidth(line.key)
+		for i, value := range wrap(line.value, width-keycol) {
+			if i == 0 {
+				fmt.Fprintf(w, "%s%s%s%s\n", indent, line.key, strings.Repeat(" ", spacer), value)
+			} else {
+				fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", keycol), value)
+			}
+		}
+	}
+
+	if len(m.Examples) > 0 {
+		fmt.Fprintf(w, "\n%s:\n", m.Message(MsgExamples))
+	}
+	for _, example := range m.Examples {
+		// command lines are not wrapped to be copied as is
+		fmt.Fprintf(w, "%s%s\n", indent, example.Cmdline)
+		if example.Explanation == "" {
+			continue
+		}
+		for _, line := range wrap(example.Explanation, width-2*len(indent)) {
+			fmt.Fprintf(w, "%s%s%s\n", indent, indent, line)
+		}
 	}
 	return nil
 }
 
+// synopsis returns the invocation followed by the option and argument items wrapped to width with
+// further lines indented to align with the first item or, if too narrow, by indent.
+func synopsis(thiscmd string, items []string, width int, indent string) string {
+	if width <= 0 || len(items) == 0 {
+		return strings.TrimSpace(thiscmd + " " + strings.Join(items, " "))
+	}
+	hang := strwidth(thiscmd) + 1
+	if width-hang < minWrapWidth {
+		hang = len(indent)
+	}
+	words := append([]string{thiscmd}, items...)
+	return strings.Join(wrapwords(words, width, width-hang), "\n"+strings.Repeat(" ", hang))
+}
+
 func optstring(opts []Option) string {
+	return itemstring(optitems(opts))
+}
+
+func argstring(args []Arg, passthrough Arg) string {
+	return itemstring(argitems(args, passthrough))
+}
+
+func itemstring(items []string) string {
 	res := ""
+	for _, item := range items {
+		res += " " + item
+	}
+	return res
+}
+
+func optitems(opts []Option) []string {
+	var res []string
 	for _, opt := range opts {
-		res += " [--" + opt.Key()
-		switch opt.Type() {
-		case TypeString:
-			res += "=string"
-		case TypeInt:
-			res += "=int"
-		case TypeNumber:
-			res += "=number"
-		}
-		res += "]"
+		res = append(res, "[--"+opt.Key()+typestr(opt.Type(), "=")+"]")
 	}
 	return res
 }
 
-func argstring(args []Arg) string {
-	res := ""
+func argitems(args []Arg, passthrough Arg) []string {
+	var res []string
 	for _, arg := range args {
 		if arg.Optional() {
-			res += " [" + arg.Key() + "]"
+			res = append(res, "["+arg.Key()+"]")
 		} else {
-			res += " <" + arg.Key() + ">"
+			res = append(res, "<"+arg.Key()+">")
 		}
 	}
+	if passthrough != nil {
+		res = append(res, "[-- <"+passthrough.Key()+">...]")
+	}
 	return res
 }
 
 func shortUsage(a App, invocation []string) string {
-	if len(invocation) < 1 {
-		return "invalid invocation path []"
-	}
-
-	cmds := a.Commands()
-	args := a.Args()
-	opts := a.Options()
-
-	if len(invocation) > 1 {
-		for _, key := range invocation[1:] {
-			matched := false
-			for _, cmd := range cmds {
-				if cmd.Key() == key {
-					cmds = cmd.Commands()
-					args = cmd.Args()
-					opts = append(opts, cmd.Options()...)
-					matched = true
-					break
-				}
-			}
-			// should never happen if invocation originates from the parser
-			if !matched {
-				return fmt.Sprintf("fatal: invalid invocation path %v", invocation)
-			}
+	sc, err := resolve(a, invocation)
+	// should never happen if invocation originates from the parser
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s%s%s", strings.Join(invocation, " "), optstring(synopsisOpts(visibleOpts(sc.global), visibleOpts(sc.opts))), argstring(sc.args, sc.passthrough))
+}
+
+// hasHelpCommand checks if the built-in help command outputting help topics is registered.
+func hasHelpCommand(a App) bool {
+	for _, cmd := range a.Commands() {
+		if c, ok := cmd.(*command); ok && c.key == helpKey && c.builtin != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func visibleOpts(opts []Option) []Option {
+	var res []Option
+	for _, opt := range opts {
+		if !opt.Hidden() {
+			res = append(res, opt)
 		}
 	}
+	return res
+}
 
-	return fmt.Sprintf("%s%s%s", strings.Join(invocation, " "), optstring(opts), argstring(args))
+func visibleCmds(cmds []Command) []Command {
+	var res []Command
+	for _, cmd := range cmds {
+		if !cmd.Hidden() {
+			res = append(res, cmd)
+		}
+	}
+	return res
 }
@@ -4,6 +4,7 @@
 package cli_test
 
 import (
+	"io"
 	"os"
 	"testing"
 
@@ -53,9 +54,11 @@ Arguments:
     fallback                branch to fallback, optional
 
 Options:
-    -v, --verbose           Verbose execution
     -b, --branch            create branch if missing
 
+Global options:
+    -v, --verbose           Verbose execution
+
 Sub-commands:
     foo checkout sub-cmd1   First sub-command
     foo checkout sub-cmd2   Second sub-command
@@ -126,3 +129,433 @@ func assertAppUsageOk(t *testing.T, expectedOutput, actualOutput string) {
 		t.Errorf("expected output: %v, found: %v", expectedOutput, actualOutput)
 	}
 }
+
+func TestApp_Usage_DeprecatedAnnotated_ok(t *testing.T) {
+	a := setupDeprecatedApp()
+	w := &stringwriter{}
+	a.Run([]string{"./foo", "-h"}, w)
+	expected := `foo
+
+Description:
+    git tool
+
+Sub-commands:
+    foo checkout   Check out a branch or revision, shortcut: co, deprecated, use switch
+`
+	assertAppUsageOk(t, expected, w.str)
+
+	w = &stringwriter{}
+	a.Run([]string{"./foo", "co", "-h"}, w)
+	expected = `foo checkout [--force] [--discard] <branch> [fallback]
+
+Description:
+    Check out a branch or revision
+
+Arguments:
+    branch          branch to checkout
+    fallback        branch to fallback, optional, deprecated
+
+Options:
+    -f, --force     Force checkout, deprecated, use --discard
+        --discard   Discard local changes
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func setupHiddenApp() cli.App {
+	complete := cli.NewCommand("__complete", "Complete the command line").
+		AsHidden()
+
+	return cli.New("git tool").
+		WithCommand(cli.NewCommand("remote", "Work with git remotes")).
+		WithCommand(complete).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("trace-internal", "Trace internals").WithType(cli.TypeBool).AsHidden())
+}
+
+func TestApp_Usage_HiddenExcluded_ok(t *testing.T) {
+	a := setupHiddenApp()
+	w := &stringwriter{}
+	a.Run([]string{"./foo", "--help"}, w)
+	expected := `foo [--verbose]
+
+Description:
+    git tool
+
+Options:
+    -v, --verbose   Verbose execution
+
+Sub-commands:
+    foo remote      Work with git remotes
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_HelpAllRevealsHidden_ok(t *testing.T) {
+	a := setupHiddenApp()
+	w := &stringwriter{}
+	code := a.Run([]string{"./foo", "--help-all"}, w)
+	if code != 0 {
+		t.Errorf("expected exit code 0, found %v", code)
+	}
+	expected := `foo [--verbose] [--trace-internal]
+
+Description:
+    git tool
+
+Options:
+    -v, --verbose          Verbose execution
+        --trace-internal   Trace internals
+
+Sub-commands:
+    foo remote             Work with git remotes
+    foo __complete         Complete the command line
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_HiddenExcludedFromShortUsage_ok(t *testing.T) {
+	a := setupHiddenApp()
+	w := &stringwriter{}
+	a.Run([]string{"./foo", "--trace-internal", "extra"}, w)
+	expected := `fatal: unknown arguments [extra]
+usage: foo [--verbose]
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func setupLocalOptsApp() cli.App {
+	add := cli.NewCommand("add", "Add a remote").
+		WithOption(cli.NewOption("tags", "Import tags").WithChar('t').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("dry", "Print only").WithChar('n').WithType(cli.TypeBool))
+
+	rmt := cli.NewCommand("remote", "Work with git remotes").
+		WithOption(cli.NewOption("dry", "Dry run").WithType(cli.TypeBool).AsLocal()).
+		WithCommand(add)
+
+	return cli.New("git tool").
+		WithCommand(rmt).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("init", "Initialize").WithType(cli.TypeBool).AsLocal())
+}
+
+func TestApp_Usage_LocalOptionsNotInherited_ok(t *testing.T) {
+	a := setupLocalOptsApp()
+	w := &stringwriter{}
+	a.Run([]string{"./foo", "remote", "add", "-h"}, w)
+	expected := `foo remote add [--verbose] [--tags] [--dry]
+
+Description:
+    Add a remote
+
+Options:
+    -t, --tags      Import tags
+    -n, --dry       Print only
+
+Global options:
+    -v, --verbose   Verbose execution
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func setupHelpApp() cli.App {
+	add := cli.NewCommand("add", "Add a remote").
+		WithArg(cli.NewArg("remote", "remote to add"))
+
+	rmt := cli.NewCommand("remote", "Work with git remotes").
+		WithShortcut("r").
+		WithCommand(add)
+
+	return cli.New("git tool").
+		WithCommand(rmt).
+		WithHelpCommand().
+		WithHelpTopic("environment", "Environment variables", "GIT_DIR    path to the repository\n")
+}
+
+func TestApp_Usage_HelpCommandForNestedCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupHelpApp().Run([]string{"./foo", "help", "r", "add"}, w)
+	if code != 0 {
+		t.Errorf("expected exit code 0, found %v", code)
+	}
+	expected := `foo remote add <remote>
+
+Description:
+    Add a remote
+
+Arguments:
+    remote   remote to add
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_HelpCommandListsTopics_ok(t *testing.T) {
+	w := &stringwriter{}
+	setupHelpApp().Run([]string{"./foo", "help"}, w)
+	expected := `foo
+
+Description:
+    git tool
+
+Sub-commands:
+    foo remote             Work with git remotes, shortcut: r
+    foo help               Show the usage for a command or a help topic
+
+Help topics:
+    foo help environment   Environment variables
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_TopicsWithoutHelpCommandNotListed_ok(t *testing.T) {
+	w := &stringwriter{}
+	a := cli.New("git tool").WithHelpTopic("environment", "Environment variables", "GIT_DIR\n")
+	a.Run([]string{"./foo", "--help"}, w)
+	expected := `foo
+
+Description:
+    git tool
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_HelpTopic_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupHelpApp().Run([]string{"./foo", "help", "environment"}, w)
+	if code != 0 {
+		t.Errorf("expected exit code 0, found %v", code)
+	}
+	assertAppUsageOk(t, "GIT_DIR    path to the repository\n", w.str)
+}
+
+func TestApp_Usage_HelpUnknownTopic_error(t *testing.T) {
+	w := &stringwriter{}
+	code := setupHelpApp().Run([]string{"./foo", "help", "remote", "foo"}, w)
+	if code != 1 {
+		t.Errorf("expected exit code 1, found %v", code)
+	}
+	expected := `fatal: unknown command or help topic foo
+usage: foo help [command]
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func setupWrapApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch or revision into the working tree updating the index").
+		WithArg(cli.NewArg("revision", "branch or revision to checkout")).
+		WithOption(cli.NewOption("branch", "create the branch if missing").WithChar('b').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("depth", "limit the history to the given number of commits").WithType(cli.TypeInt)).
+		WithOption(cli.NewOption("分支", "使用分支").WithChar('z'))
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool))
+}
+
+func TestApp_Usage_WrappedToWidth_ok(t *testing.T) {
+	w := &stringwriter{}
+	setupWrapApp().WithUsageWidth(50).Run([]string{"./foo", "checkout", "--help"}, w)
+	expected := `foo checkout [--verbose] [--branch] [--depth=int]
+             [--分支=string] <revision>
+
+Description:
+    Check out a branch or revision into the
+    working tree updating the index
+
+Arguments:
+    revision        branch or revision to checkout
+
+Options:
+    -b, --branch    create the branch if missing
+        --depth     limit the history to the given
+                    number of commits
+    -z, --分支      使用分支
+
+Global options:
+    -v, --verbose   Verbose execution
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_WrapKeepsLinesThatFit_ok(t *testing.T) {
+	w := &stringwriter{}
+	cli.New("tool:   aligned   columns\nthat fit are kept as is, longer lines are re-flowed").
+		WithUsageWidth(40).Run([]string{"./foo", "--help"}, w)
+	expected := `foo
+
+Description:
+    tool:   aligned   columns
+    that fit are kept as is, longer
+    lines are re-flowed
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_WidthFromColumns_ok(t *testing.T) {
+	columns, set := os.LookupEnv("COLUMNS")
+	defer func() {
+		if set {
+			os.Setenv("COLUMNS", columns)
+		} else {
+			os.Unsetenv("COLUMNS")
+		}
+	}()
+
+	os.Setenv("COLUMNS", "50")
+	w := &stringwriter{}
+	setupWrapApp().WithUsageWidth(cli.AutoUsageWidth).Run([]string{"./foo", "checkout", "--help"}, w)
+	wrapped := w.str
+
+	w = &stringwriter{}
+	setupWrapApp().WithUsageWidth(50).Run([]string{"./foo", "checkout", "--help"}, w)
+	assertAppUsageOk(t, w.str, wrapped)
+
+	// COLUMNS is ignored unless opted in
+	w = &stringwriter{}
+	setupWrapApp().Run([]string{"./foo", "checkout", "--help"}, w)
+	os.Unsetenv("COLUMNS")
+	unwrapped := &stringwriter{}
+	setupWrapApp().WithUsageWidth(cli.AutoUsageWidth).Run([]string{"./foo", "checkout", "--help"}, unwrapped)
+	assertAppUsageOk(t, unwrapped.str, w.str)
+	if unwrapped.str == wrapped {
+		t.Error("expected the output to differ when not wrapped")
+	}
+}
+
+func TestApp_Usage_TemplateRenderer_ok(t *testing.T) {
+	r, err := cli.NewTemplateRenderer(`USAGE: {{.Synopsis}}
+{{.Description}}
+{{range .Options}}  --{{pad .Key 10}}{{.Description}}{{deprecation .}}
+{{end}}{{range .GlobalOptions}}  --{{pad .Key 10}}{{.Description}} (global)
+{{end}}{{range .Commands}}  {{join $.Invocation " "}} {{.Key}}
+{{end}}`)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	a := setupUsageApp().WithUsageRenderer(r)
+	a.Commands()[0].WithOption(cli.NewOption("force", "overwrite changes").AsDeprecated("", "--branch"))
+
+	w := &stringwriter{}
+	a.Run([]string{"./foo", "co", "--help"}, w)
+	expected := `USAGE: foo checkout [--verbose] [--branch] [--force=string] <revision> [fallback]
+Check out a branch or revision
+  --branch    create branch if missing
+  --force     overwrite changes, deprecated, use --branch
+  --verbose   Verbose execution (global)
+  foo checkout sub-cmd1
+  foo checkout sub-cmd2
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestNewTemplateRenderer_invalidTemplate_error(t *testing.T) {
+	if _, err := cli.NewTemplateRenderer("{{.Synopsis"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+type modelRenderer struct {
+	model cli.UsageModel
+}
+
+func (r *modelRenderer) RenderUsage(m cli.UsageModel, w io.Writer) error {
+	r.model = m
+	return nil
+}
+
+func TestApp_Usage_CustomRendererModel_ok(t *testing.T) {
+	r := &modelRenderer{}
+	a := setupHelpApp().WithUsageRenderer(r)
+	a.WithOption(cli.NewOption("verbose", "Verbose execution").WithType(cli.TypeBool))
+	a.WithOption(cli.NewOption("trace", "Trace execution").WithType(cli.TypeBool).AsHidden())
+
+	a.Run([]string{"./foo", "help"}, &stringwriter{})
+	if len(r.model.Invocation) != 1 || len(r.model.HelpTopics) != 1 || len(r.model.Options) != 1 || len(r.model.Commands) != 2 {
+		t.Errorf("unexpected model for the application: %+v", r.model)
+	}
+
+	a.Run([]string{"./foo", "remote", "add", "--help-all"}, &stringwriter{})
+	m := r.model
+	if len(m.Invocation) != 3 || m.Description != "Add a remote" || len(m.Args) != 1 || len(m.Options) != 0 ||
+		len(m.GlobalOptions) != 2 || len(m.HelpTopics) != 0 {
+		t.Errorf("unexpected model for the command: %+v", m)
+	}
+}
+
+func setupGroupApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithOption(cli.NewOption("force", "Discard local changes").WithChar('f').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("depth", "History depth").WithType(cli.TypeInt).WithGroup("Networking"))
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithCommand(cli.NewCommand("cat-file", "Show object contents").WithCategory("Plumbing")).
+		WithCommand(cli.NewCommand("remote", "Work with git remotes")).
+		WithCommand(cli.NewCommand("hash-object", "Compute object ID").WithCategory("Plumbing")).
+		WithOption(cli.NewOption("proxy", "Proxy URL").WithGroup("Networking")).
+		WithOption(cli.NewOption("verbose", "Verbose execution").WithChar('v').WithType(cli.TypeBool)).
+		WithOption(cli.NewOption("color", "Color output").WithType(cli.TypeBool).WithGroup("Output"))
+}
+
+func TestApp_Usage_OptionGroupsAndCommandCategories_ok(t *testing.T) {
+	w := &stringwriter{}
+	setupGroupApp().Run([]string{"./git", "--help"}, w)
+	expected := `git [--verbose] [--proxy=string] [--color]
+
+Description:
+    git tool
+
+Options:
+    -v, --verbose     Verbose execution
+
+Networking:
+        --proxy       Proxy URL
+
+Output:
+        --color       Color output
+
+Sub-commands:
+    git checkout      Check out a branch
+    git remote        Work with git remotes
+
+Plumbing:
+    git cat-file      Show object contents
+    git hash-object   Compute object ID
+`
+	assertAppUsageOk(t, expected, w.str)
+
+	w = &stringwriter{}
+	setupGroupApp().Run([]string{"./git", "checkout", "--help"}, w)
+	expected = `git checkout [--verbose] [--force] [--proxy=string] [--depth=int] [--color] <branch>
+
+Description:
+    Check out a branch
+
+Arguments:
+    branch          branch to checkout
+
+Options:
+    -f, --force     Discard local changes
+
+Networking:
+        --depth     History depth
+        --proxy     Proxy URL
+
+Output:
+        --color     Color output
+
+Global options:
+    -v, --verbose   Verbose execution
+`
+	assertAppUsageOk(t, expected, w.str)
+}
+
+func TestApp_Usage_ShortUsageKeepsGroupsTogether_ok(t *testing.T) {
+	w := &stringwriter{}
+	setupGroupApp().Run([]string{"./git", "checkout"}, w)
+	expected := `fatal: missing required argument branch
+usage: git checkout [--verbose] [--force] [--proxy=string] [--depth=int] [--color] <branch>
+`
+	assertAppUsageOk(t, expected, w.str)
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+const (
+	versionKey  = "version"
+	versionChar = 'V'
+	jsonKey     = "json"
+)
+
+// versionFlags returns which of the built-in `--version` and `-V` flags are enabled given the options
+// permitted for the invoked command: the flags give way to options defined with the same key or char.
+func versionFlags(a App, permitted []Option) (key, char bool) {
+	if a.Version() == "" {
+		return false, false
+	}
+	key, char = true, true
+	for _, opt := range permitted {
+		if opt.Key() == versionKey {
+			// the parsed option would be indistinguishable from the flag
+			return false, false
+		}
+		if opt.CharKey() == versionChar {
+			char = false
+		}
+	}
+	return key, char
+}
+
+// versionRequested checks if the built-in version flag is given in the options parsed for the scope.
+func versionRequested(a App, sc *scope, opts map[string]string) bool {
+	_, given := opts[versionKey]
+	enabled, _ := versionFlags(a, sc.permitted())
+	return given && enabled
+}
+
+// DefaultVersionTemplate is the text/template the version is output with by default, e.g.
+// `gitc version 1.2.0, revision 3f2a9c1, go1.21.0`.
+const DefaultVersionTemplate = `{{.Name}} version {{.Version}}` +
+	`{{if .Revision}}, revision {{.Revision}}{{if .Modified}} (modified){{end}}{{end}}` +
+	`{{if .GoVersion}}, {{.GoVersion}}{{end}}` + "\n"
+
+// VersionInfo captures the version of the application along with the build information of the binary
+// as far as available, see App.WithVersion.
+type VersionInfo struct {
+	// Name is the name of the application.
+	Name string `json:"name"`
+	// Version is the version of the application as set with App.WithVersion.
+	Version string `json:"version"`
+	// Revision is the VCS revision the binary was built from.
+	Revision string `json:"revision,omitempty"`
+	// Time is the time of the VCS revision in RFC3339 format.
+	Time string `json:"time,omitempty"`
+	// Modified specifies if the working tree had local modifications at build time.
+	Modified bool `json:"modified,omitempty"`
+	// GoVersion is the version of Go the binary was built with.
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+func versionInfo(a App, appname string) VersionInfo {
+	info := readBuildInfo()
+	info.Name = appname
+	info.Version = a.Version()
+	return info
+}
+
+// writeVersion outputs the version of the application either as JSON or using the version template, an
+// error if no version is set.
+func writeVersion(a App, appname string, asjson bool, w io.Writer) error {
+	if a.Version() == "" {
+		return errorf(MsgVersionNotSet)
+	}
+	info := versionInfo(a, appname)
+	if asjson {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err == nil {
+			_, err = w.Write(append(data, '\n'))
+		}
+		return err
+	}
+	text := a.VersionTemplate()
+	if text == "" {
+		text = DefaultVersionTemplate
+	}
+	tmpl, err := template.New(versionKey).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, info)
+}
+
+// versionAction is the built-in action of the `version` command.
+func versionAction(a App, res ParseResult, env Env) int {
+	s := env.Streams
+	_, asjson := res.Opts[jsonKey]
+	if err := writeVersion(a, res.Invocation[0], asjson, s.Out); err != nil {
+		c := catalog(a, env.LookupEnv)
+		fmt.Fprintln(s.Err, msg(c, MsgFatal, errmsg(c, err)))
+		return 1
+	}
+	return 0
+}
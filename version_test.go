@@ -0,0 +1,105 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/teris-io/cli"
+)
+
+func setupVersionApp() cli.App {
+	co := cli.NewCommand("checkout", "Check out a branch").
+		WithArg(cli.NewArg("branch", "branch to checkout")).
+		WithAction(func(args []string, options map[string]string) int {
+			return 0
+		})
+
+	return cli.New("git tool").
+		WithCommand(co).
+		WithVersion("1.2.0").
+		WithVersionTemplate("{{.Name}} {{.Version}}\n")
+}
+
+func TestApp_Run_VersionFlag_ok(t *testing.T) {
+	for _, appargs := range [][]string{
+		{"./git", "--version"},
+		{"./git", "-V"},
+		{"./git", "checkout", "--version"},
+		{"./git", "checkout", "dev", "extra", "-V"},
+	} {
+		w := &stringwriter{}
+		code := setupVersionApp().Run(appargs, w)
+		assertAppRunOk(t, 0, code)
+		assertAppUsageOk(t, "git 1.2.0\n", w.str)
+	}
+}
+
+func TestApp_Parse_VersionFlagGivesWayToOptions_ok(t *testing.T) {
+	a := setupVersionApp().
+		WithCommand(cli.NewCommand("pin", "Pin a version").
+			WithOption(cli.NewOption("version", "Version to pin").WithChar('p'))).
+		WithCommand(cli.NewCommand("log", "Show the log").
+			WithOption(cli.NewOption("verify", "Verify signatures").WithChar('V').WithType(cli.TypeBool)))
+
+	invocation, args, opts, err := cli.Parse(a, []string{"./git", "pin", "--version=1.0"})
+	assertAppParseOk(t, "[git pin] [] map[version:1.0]", invocation, args, opts, err)
+	invocation, args, opts, err = cli.Parse(a, []string{"./git", "pin", "-V"})
+	assertAppParseError(t, "[git pin] [] map[]", "unknown flag -V", invocation, args, opts, err)
+	invocation, args, opts, err = cli.Parse(a, []string{"./git", "log", "-V"})
+	assertAppParseOk(t, "[git log] [] map[verify:true]", invocation, args, opts, err)
+
+	w := &stringwriter{}
+	code := a.Run([]string{"./git", "log", "--version"}, w)
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "git 1.2.0\n", w.str)
+}
+
+func TestApp_Run_VersionFlagDefaultTemplate_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := cli.New("git tool").WithVersion("1.2.0").Run([]string{"./git", "-V"}, w)
+	assertAppRunOk(t, 0, code)
+	if len(w.str) < len("git version 1.2.0\n") || w.str[:len("git version 1.2.0")] != "git version 1.2.0" {
+		t.Errorf("unexpected version output %v", w.str)
+	}
+}
+
+func TestApp_Run_VersionFlagNotEnabled_error(t *testing.T) {
+	w := &stringwriter{}
+	code := cli.New("git tool").Run([]string{"./git", "--version"}, w)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: unknown option --version\nusage: git\n", w.str)
+}
+
+func TestApp_Run_VersionInvalidTemplate_error(t *testing.T) {
+	w := &stringwriter{}
+	code := setupVersionApp().WithVersionTemplate("{{.Unknown}}").Run([]string{"./git", "-V"}, w)
+	assertAppRunOk(t, 1, code)
+}
+
+func TestApp_Run_VersionCommand_ok(t *testing.T) {
+	w := &stringwriter{}
+	code := setupVersionApp().WithVersionCommand().Run([]string{"./git", "version"}, w)
+	assertAppRunOk(t, 0, code)
+	assertAppUsageOk(t, "git 1.2.0\n", w.str)
+
+	w = &stringwriter{}
+	code = setupVersionApp().WithVersionCommand().Run([]string{"./git", "version", "--json"}, w)
+	assertAppRunOk(t, 0, code)
+	var info cli.VersionInfo
+	if err := json.Unmarshal([]byte(w.str), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "git" || info.Version != "1.2.0" {
+		t.Errorf("unexpected version info %+v", info)
+	}
+}
+
+func TestApp_Run_VersionCommandWithoutVersion_error(t *testing.T) {
+	w := &stringwriter{}
+	code := cli.New("git tool").WithVersionCommand().Run([]string{"./git", "version"}, w)
+	assertAppRunOk(t, 1, code)
+	assertAppUsageOk(t, "fatal: no version set\n", w.str)
+}
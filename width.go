@@ -0,0 +1,131 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+package cli
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// minWrapWidth is the narrowest column the usage text is wrapped to, narrower columns are not wrapped.
+const minWrapWidth = 10
+
+// widerange defines an inclusive range of East Asian wide and fullwidth characters.
+type widerange struct {
+	lo, hi rune
+}
+
+var wideranges = []widerange{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+	{0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+	{0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+	{0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4}, {0x17000, 0x18AFF}, {0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F251},
+	{0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F90C, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// runewidth returns the number of terminal columns occupied by the rune: 0 for control characters and
+// combining marks, 2 for East Asian wide and fullwidth characters and 1 otherwise.
+func runewidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	if r < wideranges[0].lo {
+		return 1
+	}
+	lo, hi := 0, len(wideranges)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideranges[mid].lo:
+			hi = mid - 1
+		case r > wideranges[mid].hi:
+			lo = mid + 1
+		default:
+			return 2
+		}
+	}
+	return 1
+}
+
+// strwidth returns the number of terminal columns occupied by the string.
+func strwidth(s string) int {
+	res := 0
+	for _, r := range s {
+		res += runewidth(r)
+	}
+	return res
+}
+
+// AutoUsageWidth configures the usage to be wrapped to the width given by the COLUMNS environment
+// variable, if set, or otherwise to the width of the terminal the usage is output to, see
+// App.WithUsageWidth.
+const AutoUsageWidth = -1
+
+// usageWidth returns the width to wrap the usage to: the configured one or, if opted in, the one given
+// by the COLUMNS environment variable looked up with lookup or the width of the terminal w refers to;
+// 0 if the usage is not to be wrapped.
+func usageWidth(a App, lookup func(string) (string, bool), w io.Writer) int {
+	width := a.UsageWidth()
+	if width == AutoUsageWidth {
+		if columns, ok := lookup("COLUMNS"); ok {
+			width, _ = strconv.Atoi(columns)
+		} else if f, ok := w.(interface{ Fd() uintptr }); ok {
+			width = termwidth(f.Fd())
+		}
+	}
+	if width < 0 {
+		return 0
+	}
+	return width
+}
+
+// wrap splits the text into lines of at most width columns breaking at white space, preserving
+// explicit line breaks. Only lines wider than width are re-flowed, others are kept as is. Words wider
+// than width are not broken. The text is not wrapped for width below minWrapWidth.
+func wrap(text string, width int) []string {
+	lines := strings.Split(text, "\n")
+	if width < minWrapWidth {
+		return lines
+	}
+	var res []string
+	for _, line := range lines {
+		if strwidth(line) <= width {
+			res = append(res, line)
+		} else {
+			res = append(res, wrapwords(strings.Fields(line), width, width)...)
+		}
+	}
+	return res
+}
+
+// wrapwords joins words with single spaces into lines of at most first columns for the first line
+// and rest columns for further ones. Words wider than the line are output on a line of their own.
+func wrapwords(words []string, first, rest int) []string {
+	res := []string{""}
+	limit, used := first, 0
+	for _, word := range words {
+		ww := strwidth(word)
+		if used > 0 && used+1+ww > limit {
+			res = append(res, "")
+			limit, used = rest, 0
+		}
+		if used > 0 {
+			res[len(res)-1] += " "
+			used++
+		}
+		res[len(res)-1] += word
+		used += ww
+	}
+	return res
+}
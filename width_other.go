@@ -0,0 +1,12 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package cli
+
+// termwidth returns 0 as the terminal size is not queried on this platform.
+func termwidth(fd uintptr) int {
+	return 0
+}
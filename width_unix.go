@@ -0,0 +1,24 @@
+// Copyright (c) 2017. Oleg Sklyar & teris.io. All rights reserved.
+// See the LICENSE file in the project root for licensing information.
+
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package cli
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// termwidth returns the width in columns of the terminal the file descriptor refers to, 0 if it does
+// not refer to a terminal.
+func termwidth(fd uintptr) int {
+	var ws struct {
+		rows, cols, xpixel, ypixel uint16
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0
+	}
+	return int(ws.cols)
+}